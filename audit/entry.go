@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ActorType is the type of the caller that made a change.
+type ActorType string
+
+// Define actor types as constants.
+const (
+	ActorUser      ActorType = "user"
+	ActorMachine   ActorType = "machine"
+	ActorAnonymous ActorType = "anonymous"
+)
+
+// Change is the old and new value of a changed field.
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Entry is a recorded write operation of the audit log.
+type Entry struct {
+	ID         uint64            `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time         `gorm:"not null;index" json:"createdAt"`
+	RequestID  string            `gorm:"size:128;index" json:"requestId"`
+	Actor      string            `gorm:"size:255;index" json:"actor"`
+	ActorType  ActorType         `gorm:"size:16;not null" json:"actorType"`
+	Method     string            `gorm:"size:16;not null" json:"method"`
+	Route      string            `gorm:"size:255;not null" json:"route"`
+	Path       string            `gorm:"size:2048;not null" json:"path"`
+	Resource   string            `gorm:"size:255;index:idx_audit_log_resource" json:"resource"`
+	ResourceID string            `gorm:"size:255;index:idx_audit_log_resource" json:"resourceId"`
+	Status     int               `gorm:"not null" json:"status"`
+	IP         string            `gorm:"size:45" json:"ip"`
+	Before     interface{}       `gorm:"type:jsonb;serializer:json" json:"before,omitempty"`
+	After      interface{}       `gorm:"type:jsonb;serializer:json" json:"after,omitempty"`
+	Diff       map[string]Change `gorm:"type:jsonb;serializer:json" json:"diff,omitempty"`
+}
+
+// TableName returns the table of the audit log.
+func (Entry) TableName() string {
+	return "audit_log"
+}
+
+// Migrate creates or updates the audit_log table and its indexes.
+//
+//	db, err := database.PostgresSQLConnection()
+//	if err := audit.Migrate(db); err != nil { }
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Entry{})
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/ArnoldPMolenaar/api-utils/pagination"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// PaginationConfig is the pagination config of the audit log, see Handler.
+var PaginationConfig = pagination.Config{
+	AllowedColumns: map[string]bool{
+		"created_at":  true,
+		"request_id":  true,
+		"actor":       true,
+		"actor_type":  true,
+		"method":      true,
+		"route":       true,
+		"path":        true,
+		"resource":    true,
+		"resource_id": true,
+		"status":      true,
+		"ip":          true,
+	},
+	DefaultSort: []pagination.SortField{{Column: "created_at", Desc: true}},
+}
+
+// Handler returns a handler that lists the audit log with the pagination query params, newest first by default.
+// Protect it, because the audit log contains the changes of all resources:
+//
+//	app.Get("/audit-log", middleware.JWTProtected(config), middleware.Require("audit:read"), audit.Handler(db))
+func Handler(db *gorm.DB) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req, err := pagination.Bind(c, PaginationConfig)
+		if req == nil {
+			return err
+		}
+
+		var total int64
+		if err := db.WithContext(c.UserContext()).Model(&Entry{}).Scopes(req.CountScopes()...).Count(&total).Error; err != nil {
+			return errors.Wrap(err, fiber.StatusInternalServerError, errors.QueryError, "Database query failed.")
+		}
+
+		entries := make([]Entry, 0)
+		if err := db.WithContext(c.UserContext()).Scopes(req.Scopes()...).Find(&entries).Error; err != nil {
+			return errors.Wrap(err, fiber.StatusInternalServerError, errors.QueryError, "Database query failed.")
+		}
+
+		model := pagination.WithLinks(c, req.Model(int(total), entries))
+		pagination.SetHeaders(c, model)
+
+		return c.JSON(model)
+	}
+}
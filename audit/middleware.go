@@ -0,0 +1,234 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/ArnoldPMolenaar/api-utils/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Define the locals keys of the audit state of a request as constants.
+const (
+	beforeLocalsKey   = "auditBefore"
+	afterLocalsKey    = "auditAfter"
+	resourceLocalsKey = "auditResource"
+)
+
+// Config defines the config of the Middleware.
+type Config struct {
+	// Methods are the methods that are recorded, POST, PUT, PATCH and DELETE by default.
+	Methods []string
+	// Resource returns the resource and its ID of the request, see SetResource.
+	// By default it is the last static segment of the route and the param after it,
+	// e.g. orders and 7 for /users/:userId/orders/:id.
+	Resource func(c *fiber.Ctx) (resource, id string)
+	// Redact are the JSON paths that are redacted in the before and after state in addition to
+	// the password, secret and token fields, see middleware.RedactConfig. Headers are not recorded.
+	Redact middleware.RedactConfig
+	// Skip skips recording the request when it returns true.
+	Skip func(c *fiber.Ctx) bool
+}
+
+// resourceRef is the resource of a request set by a handler.
+type resourceRef struct {
+	resource string
+	id       string
+}
+
+// Middleware records the write operations in the audit log with the actor, see middleware.PrincipalOf,
+// the route, method, resource, status and the diff between the before and after state of the resource.
+// Handlers set the before state with SetBefore and the after state with SetAfter,
+// the after state is the JSON response body of a successful request by default.
+// Errors of the next handlers are passed to the error handler of the app first, so the final status is recorded.
+// Add it after the authentication middleware:
+//
+//	writer := audit.NewWriter(db, audit.WriterConfig{})
+//	app.Use(middleware.JWTProtected(config), audit.Middleware(writer, audit.Config{}))
+func Middleware(recorder Recorder, config Config) func(*fiber.Ctx) error {
+	if len(config.Methods) == 0 {
+		config.Methods = []string{fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete}
+	}
+	if config.Resource == nil {
+		config.Resource = routeResource
+	}
+
+	redactor := middleware.NewRedactor(config.Redact)
+
+	return func(c *fiber.Ctx) error {
+		if !slices.Contains(config.Methods, c.Method()) || config.Skip != nil && config.Skip(c) {
+			return c.Next()
+		}
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		entry := Entry{
+			CreatedAt: time.Now(),
+			RequestID: errors.RequestID(c),
+			ActorType: ActorAnonymous,
+			Method:    c.Method(),
+			Route:     c.Route().Path,
+			Path:      c.Path(),
+			Status:    c.Response().StatusCode(),
+			IP:        c.IP(),
+		}
+
+		if principal, ok := middleware.PrincipalOf(c); ok {
+			entry.Actor = principal.Subject
+			entry.ActorType = ActorUser
+			if principal.Machine {
+				entry.ActorType = ActorMachine
+			}
+		}
+
+		if ref, ok := c.Locals(resourceLocalsKey).(resourceRef); ok {
+			entry.Resource, entry.ResourceID = ref.resource, ref.id
+		} else {
+			entry.Resource, entry.ResourceID = config.Resource(c)
+		}
+
+		entry.Before = redactor.JSON(normalize(c.Locals(beforeLocalsKey)))
+		if after := c.Locals(afterLocalsKey); after != nil {
+			entry.After = redactor.JSON(normalize(after))
+		} else if entry.Status < fiber.StatusMultipleChoices && c.Method() != fiber.MethodDelete {
+			entry.After = redactor.JSON(responseBody(c))
+		}
+		entry.Diff = Diff(entry.Before, entry.After)
+
+		recorder.Record(entry)
+
+		return nil
+	}
+}
+
+// SetBefore sets the state of the resource before the change of the request.
+func SetBefore(c *fiber.Ctx, value interface{}) {
+	c.Locals(beforeLocalsKey, value)
+}
+
+// SetAfter sets the state of the resource after the change of the request.
+func SetAfter(c *fiber.Ctx, value interface{}) {
+	c.Locals(afterLocalsKey, value)
+}
+
+// SetResource sets the resource and its ID of the request, e.g. the ID of a created resource.
+func SetResource(c *fiber.Ctx, resource, id string) {
+	c.Locals(resourceLocalsKey, resourceRef{resource: resource, id: id})
+}
+
+// Diff returns the changed fields between the before and after state by their dotted path,
+// e.g. address.city or items.0.quantity.
+func Diff(before, after interface{}) map[string]Change {
+	changes := map[string]Change{}
+	diff(changes, "", normalize(before), normalize(after))
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return changes
+}
+
+// diff adds the changed fields between the values under the path to the changes.
+func diff(changes map[string]Change, path string, before, after interface{}) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		for key, value := range beforeMap {
+			diff(changes, join(path, key), value, afterMap[key])
+		}
+		for key, value := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				diff(changes, join(path, key), nil, value)
+			}
+		}
+
+		return
+	}
+
+	beforeSlice, beforeIsSlice := before.([]interface{})
+	afterSlice, afterIsSlice := after.([]interface{})
+	if beforeIsSlice && afterIsSlice && len(beforeSlice) == len(afterSlice) {
+		for i := range beforeSlice {
+			diff(changes, join(path, strconv.Itoa(i)), beforeSlice[i], afterSlice[i])
+		}
+
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		changes[path] = Change{From: before, To: after}
+	}
+}
+
+// join appends the key to the dotted path.
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// normalize converts the value to its generic JSON representation, so structs and maps can be compared.
+func normalize(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	return decode(data)
+}
+
+// decode decodes the JSON data to its generic representation with numbers kept as json.Number.
+func decode(data []byte) interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+
+	return value
+}
+
+// responseBody returns the JSON response body of the request, or nil for other content types.
+func responseBody(c *fiber.Ctx) interface{} {
+	contentType := string(c.Response().Header.ContentType())
+	if !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+		return nil
+	}
+
+	return decode(c.Response().Body())
+}
+
+// routeResource returns the last static segment of the route and the value of the param after it.
+func routeResource(c *fiber.Ctx) (resource, id string) {
+	for _, segment := range strings.Split(strings.Trim(c.Route().Path, "/"), "/") {
+		switch {
+		case segment == "":
+		case strings.HasPrefix(segment, ":"):
+			if resource != "" {
+				id = c.Params(strings.TrimSuffix(segment[1:], "?"))
+			}
+		default:
+			resource, id = segment, ""
+		}
+	}
+
+	return resource, id
+}
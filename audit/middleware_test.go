@@ -0,0 +1,225 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/ArnoldPMolenaar/api-utils/middleware"
+	"github.com/ArnoldPMolenaar/api-utils/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type recorder []Entry
+
+func (r *recorder) Record(entry Entry) {
+	*r = append(*r, entry)
+}
+
+type order struct {
+	ID       int      `json:"id"`
+	Status   string   `json:"status"`
+	Items    []string `json:"items"`
+	Password string   `json:"password"`
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		desc   string
+		before interface{}
+		after  interface{}
+		want   map[string]Change
+	}{
+		{
+			desc:   "unchanged",
+			before: order{ID: 1, Status: "open"},
+			after:  order{ID: 1, Status: "open"},
+			want:   nil,
+		},
+		{
+			desc:   "changed fields",
+			before: order{ID: 1, Status: "open", Items: []string{"a", "b"}},
+			after:  order{ID: 1, Status: "paid", Items: []string{"a", "c"}},
+			want: map[string]Change{
+				"status":  {From: "open", To: "paid"},
+				"items.1": {From: "b", To: "c"},
+			},
+		},
+		{
+			desc:   "created",
+			before: nil,
+			after:  map[string]interface{}{"status": "open"},
+			want:   map[string]Change{"": {From: nil, To: map[string]interface{}{"status": "open"}}},
+		},
+		{
+			desc:   "added and removed keys",
+			before: map[string]interface{}{"a": map[string]interface{}{"b": 1}},
+			after:  map[string]interface{}{"a": map[string]interface{}{"c": 1}},
+			want: map[string]Change{
+				"a.b": {From: json.Number("1"), To: nil},
+				"a.c": {From: nil, To: json.Number("1")},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		if got := Diff(c.before, c.after); !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%s: Diff = %v, want %v", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var entries recorder
+
+	app := fiber.New(fiber.Config{ErrorHandler: utils.ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error {
+		if subject := c.Get("x-subject"); subject != "" {
+			c.Locals("jwtClaims", &middleware.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: subject}})
+		}
+
+		return c.Next()
+	})
+	app.Use(Middleware(&entries, Config{}))
+	app.Get("/users/:userId/orders/:id", func(c *fiber.Ctx) error {
+		return c.JSON(order{ID: 7})
+	})
+	app.Patch("/users/:userId/orders/:id", func(c *fiber.Ctx) error {
+		SetBefore(c, order{ID: 7, Status: "open", Password: "old"})
+
+		return c.JSON(order{ID: 7, Status: "paid", Password: "new"})
+	})
+	app.Post("/orders", func(c *fiber.Ctx) error {
+		SetResource(c, "orders", "8")
+
+		return c.Status(fiber.StatusCreated).JSON(order{ID: 8, Status: "open"})
+	})
+	app.Delete("/users/:userId/orders/:id", func(c *fiber.Ctx) error {
+		return errors.New(fiber.StatusNotFound, errors.NotFound, "Order not found.")
+	})
+
+	cases := []struct {
+		desc    string
+		method  string
+		target  string
+		subject string
+		want    Entry
+		diff    []string
+	}{
+		{
+			desc:    "update",
+			method:  fiber.MethodPatch,
+			target:  "/users/1/orders/7",
+			subject: "42",
+			want: Entry{
+				Actor:      "42",
+				ActorType:  ActorUser,
+				Method:     fiber.MethodPatch,
+				Route:      "/users/:userId/orders/:id",
+				Path:       "/users/1/orders/7",
+				Resource:   "orders",
+				ResourceID: "7",
+				Status:     fiber.StatusOK,
+			},
+			diff: []string{"status"},
+		},
+		{
+			desc:   "create",
+			method: fiber.MethodPost,
+			target: "/orders",
+			want: Entry{
+				ActorType:  ActorAnonymous,
+				Method:     fiber.MethodPost,
+				Route:      "/orders",
+				Path:       "/orders",
+				Resource:   "orders",
+				ResourceID: "8",
+				Status:     fiber.StatusCreated,
+			},
+			diff: []string{""},
+		},
+		{
+			desc:   "failed delete",
+			method: fiber.MethodDelete,
+			target: "/users/1/orders/9",
+			want: Entry{
+				ActorType:  ActorAnonymous,
+				Method:     fiber.MethodDelete,
+				Route:      "/users/:userId/orders/:id",
+				Path:       "/users/1/orders/9",
+				Resource:   "orders",
+				ResourceID: "9",
+				Status:     fiber.StatusNotFound,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		entries = nil
+
+		req := httptest.NewRequest(c.method, c.target, strings.NewReader(""))
+		req.Header.Set(fiber.HeaderXRequestID, "req-1")
+		if c.subject != "" {
+			req.Header.Set("x-subject", c.subject)
+		}
+		if _, err := app.Test(req); err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+
+		if len(entries) != 1 {
+			t.Fatalf("%s: entries = %v", c.desc, entries)
+		}
+		got := entries[0]
+		if got.CreatedAt.IsZero() || got.RequestID != "req-1" {
+			t.Fatalf("%s: entry = %+v", c.desc, got)
+		}
+
+		var diff []string
+		for path := range got.Diff {
+			diff = append(diff, path)
+		}
+		c.want.CreatedAt, c.want.RequestID, c.want.IP = got.CreatedAt, got.RequestID, got.IP
+		c.want.Before, c.want.After, c.want.Diff = got.Before, got.After, got.Diff
+		if !reflect.DeepEqual(got, c.want) || !reflect.DeepEqual(diff, c.diff) {
+			t.Fatalf("%s: entry = %+v, want %+v", c.desc, got, c.want)
+		}
+	}
+
+	// Reads are not recorded.
+	entries = nil
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users/1/orders/7", nil)); err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("entries = %v", entries)
+	}
+}
+
+func TestMiddlewareRedacts(t *testing.T) {
+	var entries recorder
+
+	app := fiber.New()
+	app.Use(Middleware(&entries, Config{Redact: middleware.RedactConfig{Paths: []string{"status"}}}))
+	app.Put("/orders/:id", func(c *fiber.Ctx) error {
+		SetBefore(c, order{ID: 7, Status: "open", Password: "old"})
+		SetAfter(c, order{ID: 7, Status: "paid", Password: "new"})
+
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodPut, "/orders/7", nil)); err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("entries = %v", entries)
+	}
+	after := entries[0].After.(map[string]interface{})
+	if after["password"] != middleware.Redacted || after["status"] != middleware.Redacted || entries[0].Diff != nil {
+		t.Fatalf("entry = %+v", entries[0])
+	}
+}
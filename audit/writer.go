@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Recorder records the entries of the audit log.
+type Recorder interface {
+	Record(entry Entry)
+}
+
+// WriterConfig defines the config of a Writer.
+type WriterConfig struct {
+	// BatchSize is the maximum number of entries that are inserted at once, 100 by default.
+	BatchSize int
+	// FlushInterval is the maximum time an entry waits before it is inserted, 1 second by default.
+	FlushInterval time.Duration
+	// BufferSize is the number of entries that wait to be inserted, 10000 by default.
+	// Entries are dropped and logged when the buffer is full.
+	BufferSize int
+}
+
+// Writer inserts the entries of the audit log asynchronously in batches,
+// so recording does not add database latency to the requests.
+type Writer struct {
+	mu      sync.RWMutex
+	db      *gorm.DB
+	config  WriterConfig
+	entries chan Entry
+	done    chan struct{}
+	closed  bool
+}
+
+// NewWriter creates a Writer that inserts the entries with the connection pool of the database
+// and starts inserting in the background until Close.
+//
+//	db, err := database.PostgresSQLConnection()
+//	writer := audit.NewWriter(db, audit.WriterConfig{})
+//	defer writer.Close(context.Background())
+func NewWriter(db *gorm.DB, config WriterConfig) *Writer {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 10000
+	}
+
+	w := &Writer{
+		db:      db,
+		config:  config,
+		entries: make(chan Entry, config.BufferSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+
+	return w
+}
+
+// Record queues the entry to be inserted. The entry is dropped when the writer is closed or its buffer is full.
+func (w *Writer) Record(entry Entry) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		slog.Error("audit entry dropped, writer is closed", slog.String("requestId", entry.RequestID))
+		return
+	}
+
+	select {
+	case w.entries <- entry:
+	default:
+		slog.Error("audit entry dropped, buffer is full", slog.String("requestId", entry.RequestID))
+	}
+}
+
+// Close stops accepting entries and waits until the queued entries are inserted or the context is done.
+func (w *Writer) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.entries)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run inserts the queued entries when a batch is full or the flush interval passed.
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Entry, 0, w.config.BatchSize)
+	for {
+		select {
+		case entry, ok := <-w.entries:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+
+			batch = append(batch, entry)
+			if len(batch) >= w.config.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush inserts the batch, errors are logged.
+func (w *Writer) flush(batch []Entry) {
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := w.db.CreateInBatches(batch, len(batch)).Error; err != nil {
+		slog.Error("audit entries not written", slog.Int("count", len(batch)), slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestWriter(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	var mu sync.Mutex
+	var batches []int
+	err = db.Callback().Create().After("gorm:create").Register("test:batches", func(tx *gorm.DB) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if entries, ok := tx.Statement.Dest.([]Entry); ok {
+			batches = append(batches, len(entries))
+		}
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	writer := NewWriter(db, WriterConfig{BatchSize: 2, FlushInterval: time.Hour})
+	for i := 0; i < 5; i++ {
+		writer.Record(Entry{Method: "POST"})
+	}
+	if err := writer.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	writer.Record(Entry{Method: "POST"})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 3 || batches[0] != 2 || batches[1] != 2 || batches[2] != 1 {
+		t.Fatalf("batches = %v", batches)
+	}
+}
@@ -0,0 +1,116 @@
+package errors
+
+import (
+	goerrors "errors"
+	"fmt"
+)
+
+// APIError is an error that carries everything to create its error response,
+// so service code can return it and let the ErrorHandler create the response.
+type APIError struct {
+	// Status is the HTTP status of the response.
+	Status int
+	// Code is the error code of the response.
+	Code Code
+	// Message is the message of the response.
+	Message string
+	// Details are structured details of the response.
+	Details map[string]interface{}
+	// Errors are the errors of the individual fields, params or items of the request.
+	Errors []FieldError
+	// Cause is the internal error, it is never part of the response.
+	Cause error
+	// RequestID is the ID of the request that failed, set when decoding a remote error response.
+	RequestID string
+
+	stack []uintptr
+}
+
+// New creates an APIError with a status, code and message.
+// The stack trace of the caller is recorded for logging, see Stack.
+func New(status int, code Code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message, stack: callers()}
+}
+
+// Wrap creates an APIError with a status, code and message caused by an internal error.
+// The stack trace of the caller is recorded for logging, see Stack.
+func Wrap(cause error, status int, code Code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message, Cause: cause, stack: callers()}
+}
+
+// Error returns the code, message and cause of the error.
+func (e *APIError) Error() string {
+	message := string(e.Code)
+	if e.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Message)
+	}
+	if e.Cause != nil {
+		message = fmt.Sprintf("%s: %v", message, e.Cause)
+	}
+
+	return message
+}
+
+// Unwrap returns the cause of the error.
+func (e *APIError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether the error has the target code.
+func (e *APIError) Is(target error) bool {
+	code, ok := target.(Code)
+
+	return ok && e.Code == code
+}
+
+// stackTrace returns the recorded stack trace, nil when the error was not created by New or Wrap.
+func (e *APIError) stackTrace() []uintptr {
+	return e.stack
+}
+
+// WithDetail returns a copy of the error with the detail added.
+func (e *APIError) WithDetail(key string, value interface{}) *APIError {
+	return e.WithDetails(map[string]interface{}{key: value})
+}
+
+// WithDetails returns a copy of the error with the details added.
+func (e *APIError) WithDetails(details map[string]interface{}) *APIError {
+	clone := *e
+	clone.Details = make(map[string]interface{}, len(e.Details)+len(details))
+	for key, value := range e.Details {
+		clone.Details[key] = value
+	}
+	for key, value := range details {
+		clone.Details[key] = value
+	}
+
+	return &clone
+}
+
+// WithErrors returns a copy of the error with the field errors added.
+func (e *APIError) WithErrors(errs ...FieldError) *APIError {
+	clone := *e
+	clone.Errors = append(append([]FieldError{}, e.Errors...), errs...)
+
+	return &clone
+}
+
+// Is reports whether any error in err's tree matches target, see the standard errors.Is.
+func Is(err, target error) bool {
+	return goerrors.Is(err, target)
+}
+
+// As finds the first error in err's tree that matches target, see the standard errors.As.
+func As(err error, target interface{}) bool {
+	return goerrors.As(err, target)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, see the standard errors.Unwrap.
+func Unwrap(err error) error {
+	return goerrors.Unwrap(err)
+}
+
+// Join returns an error that wraps the given errors, see the standard errors.Join.
+func Join(errs ...error) error {
+	return goerrors.Join(errs...)
+}
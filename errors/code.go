@@ -1,17 +1,33 @@
 package errors
 
+// Code is an error code of the API.
+// It implements error, so errors.Is(err, errors.NotFound) matches an *APIError with that code,
+// and a handler can return a code, which is rendered with its registered status and message, see Translate.
+//
+// Breaking change: the codes used to be untyped string constants. Convert them with string(code)
+// where a string is expected, e.g. when assigning to a string or in a switch on a string.
+type Code string
+
+// Error returns the code as error message.
+func (c Code) Error() string {
+	return string(c)
+}
+
 // Define error codes as constants.
 const (
-	NotFound             = "notFound"
-	Unauthorized         = "unauthorized"
-	InternalServerError  = "internalServerError"
-	BodyParse            = "bodyParse"
-	Validator            = "validator"
-	QueryError           = "queryError"
-	CacheError           = "cacheError"
-	Forbidden            = "forbidden"
-	MissingRequiredParam = "missingRequiredParam"
-	InvalidParam         = "invalidParam"
-	OutOfSync            = "outOfSync"
-	// Add more error codes as needed.
+	NotFound             Code = "notFound"
+	Unauthorized         Code = "unauthorized"
+	InternalServerError  Code = "internalServerError"
+	BodyParse            Code = "bodyParse"
+	Validator            Code = "validator"
+	QueryError           Code = "queryError"
+	CacheError           Code = "cacheError"
+	Forbidden            Code = "forbidden"
+	MissingRequiredParam Code = "missingRequiredParam"
+	InvalidParam         Code = "invalidParam"
+	OutOfSync            Code = "outOfSync"
+	Conflict             Code = "conflict"
+	Timeout              Code = "timeout"
+	TooManyRequests      Code = "tooManyRequests"
+	// Services add their own error codes with Register.
 )
@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// maxDecodeBodySize is the maximum number of bytes of an error response body that is decoded.
+const maxDecodeBodySize = 1 << 20
+
+// errorBody is the body of an error response in the JSON or problem details format.
+type errorBody struct {
+	Code      Code                   `json:"code"`
+	Message   json.RawMessage        `json:"message"`
+	Detail    string                 `json:"detail"`
+	Title     string                 `json:"title"`
+	Details   map[string]interface{} `json:"details"`
+	Errors    []FieldError           `json:"errors"`
+	RequestID string                 `json:"requestId"`
+}
+
+// Decode turns the error response of another service into an *APIError with its status, code,
+// message, details, errors and request ID, so callers can use errors.Is(err, errors.NotFound).
+// It returns nil for a response that is not an error. The body is read but not closed.
+func Decode(resp *http.Response) error {
+	if resp.StatusCode < fiber.StatusBadRequest {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDecodeBodySize))
+	if err != nil {
+		return Wrap(err, resp.StatusCode, codeForStatus(resp.StatusCode), http.StatusText(resp.StatusCode))
+	}
+
+	return decode(resp.StatusCode, resp.Header.Get(fiber.HeaderXRequestID), body)
+}
+
+// DecodeFastHTTP turns the fasthttp error response of another service into an *APIError, see Decode.
+func DecodeFastHTTP(resp *fasthttp.Response) error {
+	if resp.StatusCode() < fiber.StatusBadRequest {
+		return nil
+	}
+
+	body := resp.Body()
+	if len(body) > maxDecodeBodySize {
+		body = body[:maxDecodeBodySize]
+	}
+
+	return decode(resp.StatusCode(), string(resp.Header.Peek(fiber.HeaderXRequestID)), body)
+}
+
+// decode creates the APIError of an error response.
+// A body that is not in the JSON or problem details format results in the code of the status
+// and the body text as message.
+func decode(status int, requestID string, body []byte) *APIError {
+	apiErr := &APIError{Status: status, RequestID: requestID}
+
+	var parsed errorBody
+	if err := json.Unmarshal(body, &parsed); err != nil || parsed.Code == "" {
+		apiErr.Code = codeForStatus(status)
+		apiErr.Message = strings.TrimSpace(string(body))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(status)
+		}
+
+		return apiErr
+	}
+
+	apiErr.Code = parsed.Code
+	apiErr.Details = parsed.Details
+	apiErr.Errors = parsed.Errors
+	if parsed.RequestID != "" {
+		apiErr.RequestID = parsed.RequestID
+	}
+
+	// The message is a string, or structured like validation errors which are kept in the details.
+	var message string
+	switch {
+	case len(parsed.Message) > 0 && json.Unmarshal(parsed.Message, &message) == nil:
+		apiErr.Message = message
+	case len(parsed.Message) > 0 && string(parsed.Message) != "null":
+		var structured interface{}
+		_ = json.Unmarshal(parsed.Message, &structured)
+		apiErr = apiErr.WithDetail("message", structured)
+	case parsed.Detail != "":
+		apiErr.Message = parsed.Detail
+	}
+
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(status)
+	}
+
+	return apiErr
+}
+
+// codeForStatus returns the error code of a status for responses without code.
+func codeForStatus(status int) Code {
+	switch status {
+	case fiber.StatusNotFound:
+		return NotFound
+	case fiber.StatusUnauthorized:
+		return Unauthorized
+	case fiber.StatusForbidden:
+		return Forbidden
+	case fiber.StatusConflict:
+		return Conflict
+	case fiber.StatusPreconditionFailed:
+		return OutOfSync
+	case fiber.StatusGatewayTimeout:
+		return Timeout
+	case fiber.StatusTooManyRequests:
+		return TooManyRequests
+	}
+
+	if status >= fiber.StatusInternalServerError {
+		return InternalServerError
+	}
+
+	return InvalidParam
+}
@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		status    int
+		header    string
+		body      string
+		code      Code
+		message   string
+		details   map[string]interface{}
+		requestID string
+		desc      string
+	}{
+		{
+			status:  fiber.StatusNotFound,
+			body:    `{"code":"notFound","message":"User not found.","details":{"id":7}}`,
+			header:  "req-1",
+			code:    NotFound,
+			message: "User not found.",
+			details: map[string]interface{}{"id": float64(7)},
+			// The request ID of the header is used when the body has none.
+			requestID: "req-1",
+			desc:      "json",
+		},
+		{
+			status:    fiber.StatusConflict,
+			body:      `{"type":"about:blank","title":"Conflict","status":409,"detail":"Resource already exists.","code":"conflict","requestId":"req-2"}`,
+			code:      Conflict,
+			message:   "Resource already exists.",
+			requestID: "req-2",
+			desc:      "problem details",
+		},
+		{
+			status:  fiber.StatusBadRequest,
+			body:    `{"code":"validator","message":{"Name":"Name is a required field"}}`,
+			code:    Validator,
+			message: "Bad Request",
+			details: map[string]interface{}{"message": map[string]interface{}{"Name": "Name is a required field"}},
+			desc:    "structured message",
+		},
+		{
+			status:  fiber.StatusBadGateway,
+			body:    "upstream connect error",
+			code:    InternalServerError,
+			message: "upstream connect error",
+			desc:    "plain text",
+		},
+	}
+
+	for _, c := range cases {
+		resp := &http.Response{StatusCode: c.status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(c.body))}
+		resp.Header.Set(fiber.HeaderXRequestID, c.header)
+
+		fastResp := &fasthttp.Response{}
+		fastResp.SetStatusCode(c.status)
+		fastResp.Header.Set(fiber.HeaderXRequestID, c.header)
+		fastResp.SetBodyString(c.body)
+
+		for name, err := range map[string]error{"Decode": Decode(resp), "DecodeFastHTTP": DecodeFastHTTP(fastResp)} {
+			var apiErr *APIError
+			if !As(err, &apiErr) {
+				t.Fatalf("%s: %s() = %v, want *APIError", c.desc, name, err)
+			}
+			if apiErr.Status != c.status || apiErr.Code != c.code || apiErr.Message != c.message ||
+				apiErr.RequestID != c.requestID || !reflect.DeepEqual(apiErr.Details, c.details) {
+				t.Fatalf("%s: %s() = %+v", c.desc, name, apiErr)
+			}
+			if !Is(err, c.code) {
+				t.Fatalf("%s: Is(%s(), %s) = false", c.desc, name, c.code)
+			}
+		}
+	}
+
+	ok := &http.Response{StatusCode: fiber.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}
+	if err := Decode(ok); err != nil {
+		t.Fatalf("Decode() of a successful response = %v, want nil", err)
+	}
+}
@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes a single problem with a field, param or item of the request.
+type FieldError struct {
+	// Pointer is the RFC 6901 JSON pointer to the field in the request body, e.g. /items/0/name.
+	Pointer string `json:"pointer,omitempty"`
+	// Param is the name of the query param, e.g. searchEq.
+	Param string `json:"param,omitempty"`
+	// Code is the error code of the problem.
+	Code Code `json:"code"`
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// Error returns the location and message of the problem.
+func (e FieldError) Error() string {
+	switch {
+	case e.Pointer != "":
+		return fmt.Sprintf("%s: %s", e.Pointer, e.Message)
+	case e.Param != "":
+		return fmt.Sprintf("%s: %s", e.Param, e.Message)
+	default:
+		return e.Message
+	}
+}
+
+// Pointer builds an RFC 6901 JSON pointer of the path segments, e.g. Pointer("items", 0, "name") is /items/0/name.
+func Pointer(segments ...interface{}) string {
+	var builder strings.Builder
+	for _, segment := range segments {
+		var text string
+		switch s := segment.(type) {
+		case int:
+			text = strconv.Itoa(s)
+		default:
+			text = fmt.Sprint(s)
+		}
+
+		// Escape ~ and / as defined by RFC 6901.
+		text = strings.ReplaceAll(text, "~", "~0")
+		text = strings.ReplaceAll(text, "/", "~1")
+		builder.WriteString("/")
+		builder.WriteString(text)
+	}
+
+	return builder.String()
+}
+
+// Bulk collects the errors of the items of a bulk operation.
+//
+//	var bulk errors.Bulk
+//	for i, item := range items {
+//		if err := save(item); err != nil {
+//			bulk.Add(i, err)
+//		}
+//	}
+//	if err := bulk.Err(fiber.StatusBadRequest, errors.InvalidParam, "Some items are invalid."); err != nil {
+//		return err
+//	}
+type Bulk struct {
+	errs []FieldError
+}
+
+// Add adds the error of the item at the index.
+// The code and message of an *APIError are used, as are its field errors below the item.
+// The message of other errors is only used when marked safe, see Safe.
+func (b *Bulk) Add(index int, err error) {
+	var apiErr *APIError
+	switch {
+	case As(err, &apiErr) && len(apiErr.Errors) > 0:
+		for _, fieldErr := range apiErr.Errors {
+			fieldErr.Pointer = Pointer(index) + fieldErr.Pointer
+			b.errs = append(b.errs, fieldErr)
+		}
+	case As(err, &apiErr):
+		b.AddField(index, "", apiErr.Code, apiErr.Message)
+	case IsSafe(err):
+		b.AddField(index, "", InternalServerError, err.Error())
+	default:
+		b.AddField(index, "", InternalServerError, InternalErrorMessage)
+	}
+}
+
+// AddField adds a problem with a field of the item at the index, an empty field refers to the item itself.
+func (b *Bulk) AddField(index int, field string, code Code, message string) {
+	pointer := Pointer(index)
+	if field != "" {
+		pointer = Pointer(index, field)
+	}
+
+	b.errs = append(b.errs, FieldError{Pointer: pointer, Code: code, Message: message})
+}
+
+// Errors returns the collected errors.
+func (b *Bulk) Errors() []FieldError {
+	return b.errs
+}
+
+// Err returns an *APIError with the collected errors, or nil when no errors were collected.
+func (b *Bulk) Err(status int, code Code, message string) error {
+	if len(b.errs) == 0 {
+		return nil
+	}
+
+	apiErr := New(status, code, message).WithErrors(b.errs...)
+	apiErr.stack = callers()
+
+	return apiErr
+}
@@ -0,0 +1,58 @@
+package errors
+
+import (
+	goerrors "errors"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestPointer(t *testing.T) {
+	cases := []struct {
+		segments []interface{}
+		pointer  string
+		desc     string
+	}{
+		{segments: nil, pointer: "", desc: "root"},
+		{segments: []interface{}{"items", 0, "name"}, pointer: "/items/0/name", desc: "nested"},
+		{segments: []interface{}{"a/b", "c~d"}, pointer: "/a~1b/c~0d", desc: "escaped"},
+	}
+
+	for _, c := range cases {
+		if got := Pointer(c.segments...); got != c.pointer {
+			t.Fatalf("%s: Pointer() = %q, want %q", c.desc, got, c.pointer)
+		}
+	}
+}
+
+func TestBulk(t *testing.T) {
+	var bulk Bulk
+	if err := bulk.Err(fiber.StatusBadRequest, InvalidParam, "Some items are invalid."); err != nil {
+		t.Fatalf("Err() without errors = %v, want nil", err)
+	}
+
+	bulk.AddField(0, "name", Validator, "name is required")
+	bulk.Add(1, New(fiber.StatusConflict, Conflict, "User already exists."))
+	bulk.Add(2, New(fiber.StatusBadRequest, Validator, "Item is invalid.").
+		WithErrors(FieldError{Pointer: "/email", Code: Validator, Message: "email is invalid"}))
+	bulk.Add(3, goerrors.New("connection refused"))
+
+	want := []FieldError{
+		{Pointer: "/0/name", Code: Validator, Message: "name is required"},
+		{Pointer: "/1", Code: Conflict, Message: "User already exists."},
+		{Pointer: "/2/email", Code: Validator, Message: "email is invalid"},
+		{Pointer: "/3", Code: InternalServerError, Message: InternalErrorMessage},
+	}
+	if got := bulk.Errors(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Errors() = %+v, want %+v", got, want)
+	}
+
+	var apiErr *APIError
+	if !As(bulk.Err(fiber.StatusBadRequest, InvalidParam, "Some items are invalid."), &apiErr) {
+		t.Fatalf("Err() is not an *APIError")
+	}
+	if apiErr.Status != fiber.StatusBadRequest || len(apiErr.Errors) != len(want) {
+		t.Fatalf("Err() = %+v", apiErr)
+	}
+}
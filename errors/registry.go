@@ -0,0 +1,215 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/ArnoldPMolenaar/api-utils/i18n"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CodeInfo describes a registered error code.
+type CodeInfo struct {
+	// Code is the error code.
+	Code Code `json:"code"`
+	// Status is the default HTTP status of the code.
+	Status int `json:"status"`
+	// Message is the default message of the code.
+	Message string `json:"message"`
+	// Description explains when the code is returned.
+	Description string `json:"description"`
+}
+
+// registry holds the registered error codes.
+var registry = struct {
+	sync.RWMutex
+	codes map[Code]CodeInfo
+}{codes: map[Code]CodeInfo{}}
+
+// init registers the error codes of this package.
+func init() {
+	for _, info := range []CodeInfo{
+		{Code: NotFound, Status: fiber.StatusNotFound, Message: "Resource not found.", Description: "The requested resource or endpoint does not exist."},
+		{Code: Unauthorized, Status: fiber.StatusUnauthorized, Message: "Authentication is required.", Description: "The request has missing or invalid credentials."},
+		{Code: InternalServerError, Status: fiber.StatusInternalServerError, Message: "An internal error occurred.", Description: "The request failed because of an unexpected error."},
+		{Code: BodyParse, Status: fiber.StatusBadRequest, Message: "Request body cannot be parsed.", Description: "The request body is not valid for its content type."},
+		{Code: Validator, Status: fiber.StatusBadRequest, Message: "Request body is invalid.", Description: "The request body does not pass validation."},
+		{Code: QueryError, Status: fiber.StatusInternalServerError, Message: "Database query failed.", Description: "A database query of the request failed."},
+		{Code: CacheError, Status: fiber.StatusInternalServerError, Message: "Cache operation failed.", Description: "A cache operation of the request failed."},
+		{Code: Forbidden, Status: fiber.StatusForbidden, Message: "Access is denied.", Description: "The caller is authenticated but lacks the required permission."},
+		{Code: MissingRequiredParam, Status: fiber.StatusBadRequest, Message: "Required param is missing.", Description: "A required param or field of the request is missing."},
+		{Code: InvalidParam, Status: fiber.StatusBadRequest, Message: "Param is invalid.", Description: "A param of the request has an invalid value."},
+		{Code: OutOfSync, Status: fiber.StatusPreconditionFailed, Message: "Resource is out of sync.", Description: "The resource changed since the client last read it."},
+		{Code: Conflict, Status: fiber.StatusConflict, Message: "Resource conflicts with the current state.", Description: "The request conflicts with an existing or concurrently changed resource."},
+		{Code: Timeout, Status: fiber.StatusGatewayTimeout, Message: "Request took too long to complete.", Description: "The request was canceled because it exceeded its time limit."},
+		{Code: TooManyRequests, Status: fiber.StatusTooManyRequests, Message: "Too many requests, please try again later.", Description: "The caller exceeded its rate limit, see the Retry-After header."},
+	} {
+		MustRegister(info)
+	}
+}
+
+// Register adds the error code to the registry, so it is part of the exported catalog
+// and can be created with FromCode. The message is the English default of the code for i18n.Translate,
+// so other languages only need a translation of the code. Registering a code twice returns an error.
+func Register(info CodeInfo) error {
+	if info.Code == "" {
+		return fmt.Errorf("error code is empty")
+	}
+	if info.Status < 100 || info.Status > 599 {
+		return fmt.Errorf("error code %q has invalid status %d", info.Code, info.Status)
+	}
+
+	registry.Lock()
+	if _, ok := registry.codes[info.Code]; ok {
+		registry.Unlock()
+		return fmt.Errorf("error code %q is already registered", info.Code)
+	}
+	registry.codes[info.Code] = info
+	registry.Unlock()
+
+	if info.Message != "" {
+		i18n.RegisterBundle(i18n.DefaultLanguage, i18n.Bundle{Codes: map[string]string{string(info.Code): info.Message}})
+	}
+
+	return nil
+}
+
+// MustRegister adds the error code to the registry and panics when it cannot be registered.
+// It is meant to be called from init functions.
+func MustRegister(info CodeInfo) {
+	if err := Register(info); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the registered info of the error code.
+func Lookup(code Code) (CodeInfo, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	info, ok := registry.codes[code]
+
+	return info, ok
+}
+
+// Codes returns all registered error codes sorted by code.
+func Codes() []CodeInfo {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	codes := make([]CodeInfo, 0, len(registry.codes))
+	for _, info := range registry.codes {
+		codes = append(codes, info)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].Code < codes[j].Code
+	})
+
+	return codes
+}
+
+// FromCode creates an APIError with the default status and message of the registered code.
+// An unregistered code results in an internal server error status.
+func FromCode(code Code) *APIError {
+	info, ok := Lookup(code)
+	if !ok {
+		info = CodeInfo{Status: fiber.StatusInternalServerError, Message: string(code)}
+	}
+
+	return &APIError{Status: info.Status, Code: code, Message: info.Message, stack: callers()}
+}
+
+// ExportJSON writes the catalog of registered error codes as a JSON array.
+func ExportJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(Codes())
+}
+
+// ExportOpenAPI writes the catalog of registered error codes as OpenAPI 3 components:
+// an ErrorCode enum schema, the Error, FieldError and ProblemDetails response schemas
+// and a reusable response per error code.
+func ExportOpenAPI(w io.Writer) error {
+	codes := Codes()
+
+	enum := make([]Code, len(codes))
+	descriptions := make(map[Code]string, len(codes))
+	responses := make(map[Code]interface{}, len(codes))
+	for i, info := range codes {
+		enum[i] = info.Code
+		descriptions[info.Code] = info.Description
+		responses[info.Code] = map[string]interface{}{
+			"description": fmt.Sprintf("%d %s", info.Status, info.Description),
+			"content": map[string]interface{}{
+				fiber.MIMEApplicationJSON: map[string]interface{}{
+					"schema":  map[string]interface{}{"$ref": "#/components/schemas/Error"},
+					"example": map[string]interface{}{"code": info.Code, "message": info.Message},
+				},
+				MIMEApplicationProblemJSON: map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/ProblemDetails"},
+				},
+			},
+		}
+	}
+
+	details := map[string]interface{}{"type": "object", "additionalProperties": true}
+	fieldErrors := map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/FieldError"}}
+	document := map[string]interface{}{
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"ErrorCode": map[string]interface{}{
+					"type":                "string",
+					"enum":                enum,
+					"x-enum-descriptions": descriptions,
+				},
+				"Error": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"code", "message"},
+					"properties": map[string]interface{}{
+						"code":      map[string]interface{}{"$ref": "#/components/schemas/ErrorCode"},
+						"message":   map[string]interface{}{},
+						"details":   details,
+						"errors":    fieldErrors,
+						"requestId": map[string]interface{}{"type": "string"},
+					},
+				},
+				"FieldError": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"code", "message"},
+					"properties": map[string]interface{}{
+						"pointer": map[string]interface{}{"type": "string", "format": "json-pointer"},
+						"param":   map[string]interface{}{"type": "string"},
+						"code":    map[string]interface{}{"$ref": "#/components/schemas/ErrorCode"},
+						"message": map[string]interface{}{"type": "string"},
+					},
+				},
+				"ProblemDetails": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"type", "title", "status", "code"},
+					"properties": map[string]interface{}{
+						"type":      map[string]interface{}{"type": "string", "format": "uri-reference"},
+						"title":     map[string]interface{}{"type": "string"},
+						"status":    map[string]interface{}{"type": "integer"},
+						"detail":    map[string]interface{}{"type": "string"},
+						"instance":  map[string]interface{}{"type": "string", "format": "uri-reference"},
+						"code":      map[string]interface{}{"$ref": "#/components/schemas/ErrorCode"},
+						"message":   map[string]interface{}{},
+						"details":   details,
+						"errors":    fieldErrors,
+						"requestId": map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+			"responses": responses,
+		},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(document)
+}
@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ArnoldPMolenaar/api-utils/i18n"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRegister(t *testing.T) {
+	info := CodeInfo{Code: "registryTestPaymentDeclined", Status: fiber.StatusPaymentRequired, Message: "Payment was declined."}
+
+	if err := Register(info); err != nil {
+		t.Fatalf("Register(%s) unexpected error: %v", info.Code, err)
+	}
+	if err := Register(info); err == nil {
+		t.Fatalf("Register(%s) twice expected error", info.Code)
+	}
+	if err := Register(CodeInfo{Code: NotFound, Status: fiber.StatusNotFound}); err == nil {
+		t.Fatalf("Register(%s) of a built-in code expected error", NotFound)
+	}
+	if err := Register(CodeInfo{Code: "registryTestInvalidStatus", Status: 42}); err == nil {
+		t.Fatalf("Register() with invalid status expected error")
+	}
+
+	apiErr := FromCode(info.Code)
+	if apiErr.Status != info.Status || apiErr.Message != info.Message || !Is(apiErr, info.Code) {
+		t.Fatalf("FromCode(%s) = %+v", info.Code, apiErr)
+	}
+	if apiErr := FromCode("registryTestUnknown"); apiErr.Status != fiber.StatusInternalServerError {
+		t.Fatalf("FromCode() of an unknown code = %+v", apiErr)
+	}
+
+	i18n.RegisterBundle("nl", i18n.Bundle{Codes: map[string]string{string(info.Code): "Betaling is geweigerd."}})
+	if got := i18n.Translate("nl", string(info.Code), info.Message); got != "Betaling is geweigerd." {
+		t.Fatalf("Translate() of the registered message = %q", got)
+	}
+	if got := i18n.Translate("nl", string(Forbidden), "Access is denied."); got != "Toegang geweigerd." {
+		t.Fatalf("Translate() of a built-in message = %q", got)
+	}
+}
+
+func TestExport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportJSON(&buf); err != nil {
+		t.Fatalf("ExportJSON() unexpected error: %v", err)
+	}
+
+	var codes []CodeInfo
+	if err := json.Unmarshal(buf.Bytes(), &codes); err != nil {
+		t.Fatalf("ExportJSON() wrote invalid JSON: %v", err)
+	}
+	if len(codes) != len(Codes()) {
+		t.Fatalf("ExportJSON() wrote %d codes, want %d", len(codes), len(Codes()))
+	}
+
+	buf.Reset()
+	if err := ExportOpenAPI(&buf); err != nil {
+		t.Fatalf("ExportOpenAPI() unexpected error: %v", err)
+	}
+
+	var document struct {
+		Components struct {
+			Schemas struct {
+				ErrorCode struct {
+					Enum []string `json:"enum"`
+				} `json:"ErrorCode"`
+			} `json:"schemas"`
+			Responses map[string]interface{} `json:"responses"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatalf("ExportOpenAPI() wrote invalid JSON: %v", err)
+	}
+	if len(document.Components.Schemas.ErrorCode.Enum) != len(codes) || document.Components.Responses[string(NotFound)] == nil {
+		t.Fatalf("ExportOpenAPI() wrote an incomplete catalog: %s", buf.String())
+	}
+}
@@ -0,0 +1,41 @@
+package errors
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// maxRequestIDLength is the maximum length of a request ID accepted from the client.
+const maxRequestIDLength = 128
+
+// RequestID returns the ID to correlate the request with its logs and error response.
+// The ID already set on the response is used, otherwise a valid X-Request-ID header of the request,
+// otherwise a new UUID. The ID is set as X-Request-ID header on the response.
+func RequestID(c *fiber.Ctx) string {
+	id := c.GetRespHeader(fiber.HeaderXRequestID)
+	if id == "" {
+		id = c.Get(fiber.HeaderXRequestID)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set(fiber.HeaderXRequestID, id)
+	}
+
+	return id
+}
+
+// validRequestID reports whether the request ID is safe to use in headers and logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		isAlphanumeric := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+		if !isAlphanumeric && r != '-' && r != '_' && r != '.' && r != ':' {
+			return false
+		}
+	}
+
+	return true
+}
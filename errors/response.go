@@ -1,11 +1,122 @@
 package errors
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ArnoldPMolenaar/api-utils/i18n"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// MIMEApplicationProblemJSON is the content type of RFC 7807 problem details.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// InternalErrorMessage is returned in production instead of the message of an internal error.
+const InternalErrorMessage = "An internal error occurred, please contact support with the request ID."
+
+// Define error response formats as constants.
+const (
+	FormatJSON    = "json"
+	FormatProblem = "problem"
+)
 
 // Response creates a JSON response with a message and code.
+// A string message is translated to the Accept-Language of the request, see i18n.Message.
+// It creates an RFC 7807 problem details response instead when ERROR_FORMAT=problem
+// is configured in the .env file or when the client accepts application/problem+json.
 func Response(c *fiber.Ctx, status int, code, message interface{}) error {
-	return c.Status(status).JSON(fiber.Map{
-		"code":    code,
-		"message": message,
-	})
+	return ResponseWithDetails(c, status, code, message, nil)
+}
+
+// ResponseWithDetails creates a JSON response with a message, code and structured details, see Response.
+// The details are left out when empty.
+func ResponseWithDetails(c *fiber.Ctx, status int, code, message interface{}, details map[string]interface{}) error {
+	return writeResponse(c, status, code, message, details, nil)
+}
+
+// ResponseWithErrors creates a JSON response with a message, code and the errors of
+// the individual fields, params or items of the request, see Response.
+// The errors are left out when empty.
+func ResponseWithErrors(c *fiber.Ctx, status int, code, message interface{}, errs []FieldError) error {
+	return writeResponse(c, status, code, message, nil, errs)
+}
+
+// ErrorResponse creates the JSON response of the API error with its details and errors, see Response.
+func ErrorResponse(c *fiber.Ctx, err *APIError) error {
+	return writeResponse(c, err.Status, err.Code, err.Message, err.Details, err.Errors)
+}
+
+// writeResponse writes the error response in the format of the request.
+// The request ID is added when it is set on the response.
+func writeResponse(c *fiber.Ctx, status int, code, message interface{}, details map[string]interface{}, errs []FieldError) error {
+	if text, ok := message.(string); ok {
+		message = i18n.Message(c, fmt.Sprint(code), text)
+		c.Set(fiber.HeaderContentLanguage, i18n.Language(c))
+	}
+
+	problem := UseProblemDetails(c)
+
+	var body fiber.Map
+	if problem {
+		body = problemDetails(c, status, code, message)
+	} else {
+		body = fiber.Map{
+			"code":    code,
+			"message": message,
+		}
+	}
+
+	if len(details) > 0 {
+		body["details"] = details
+	}
+
+	if len(errs) > 0 {
+		body["errors"] = errs
+	}
+
+	if requestID := c.GetRespHeader(fiber.HeaderXRequestID); requestID != "" {
+		body["requestId"] = requestID
+	}
+
+	if problem {
+		return c.Status(status).JSON(body, MIMEApplicationProblemJSON)
+	}
+
+	return c.Status(status).JSON(body)
+}
+
+// UseProblemDetails reports whether the error response of the request is written as problem details.
+func UseProblemDetails(c *fiber.Ctx) bool {
+	if os.Getenv("ERROR_FORMAT") == FormatProblem {
+		return true
+	}
+
+	return strings.Contains(c.Get(fiber.HeaderAccept), MIMEApplicationProblemJSON)
+}
+
+// problemDetails creates the problem details document with the code as extension member.
+// The type is ERROR_TYPE_URL from the .env file followed by the code, or about:blank when not configured.
+// A message that is not a string is kept in the message extension member.
+func problemDetails(c *fiber.Ctx, status int, code, message interface{}) fiber.Map {
+	problem := fiber.Map{
+		"type":     "about:blank",
+		"title":    utils.StatusMessage(status),
+		"status":   status,
+		"instance": c.OriginalURL(),
+		"code":     code,
+	}
+
+	if typeURL := os.Getenv("ERROR_TYPE_URL"); typeURL != "" {
+		problem["type"] = fmt.Sprintf("%s/%v", strings.TrimSuffix(typeURL, "/"), code)
+	}
+
+	if detail, ok := message.(string); ok {
+		problem["detail"] = detail
+	} else {
+		problem["message"] = message
+	}
+
+	return problem
 }
@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestResponse(t *testing.T) {
+	cases := []struct {
+		format      string
+		typeURL     string
+		accept      string
+		message     interface{}
+		contentType string
+		body        map[string]interface{}
+		desc        string
+	}{
+		{
+			message:     "Machine key is invalid.",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        map[string]interface{}{"code": string(Unauthorized), "message": "Machine key is invalid."},
+			desc:        "json",
+		},
+		{
+			format:      FormatProblem,
+			message:     "Machine key is invalid.",
+			contentType: MIMEApplicationProblemJSON,
+			body: map[string]interface{}{
+				"type":     "about:blank",
+				"title":    "Unauthorized",
+				"status":   float64(fiber.StatusUnauthorized),
+				"detail":   "Machine key is invalid.",
+				"instance": "/users?page=1",
+				"code":     string(Unauthorized),
+			},
+			desc: "configured problem details",
+		},
+		{
+			typeURL:     "https://errors.example.com/",
+			accept:      "application/problem+json, application/json;q=0.9",
+			message:     map[string]string{"name": "required"},
+			contentType: MIMEApplicationProblemJSON,
+			body: map[string]interface{}{
+				"type":     "https://errors.example.com/unauthorized",
+				"title":    "Unauthorized",
+				"status":   float64(fiber.StatusUnauthorized),
+				"message":  map[string]interface{}{"name": "required"},
+				"instance": "/users?page=1",
+				"code":     string(Unauthorized),
+			},
+			desc: "accepted problem details",
+		},
+	}
+
+	for _, c := range cases {
+		t.Setenv("ERROR_FORMAT", c.format)
+		t.Setenv("ERROR_TYPE_URL", c.typeURL)
+
+		app := fiber.New()
+		app.Get("/users", func(ctx *fiber.Ctx) error {
+			return Response(ctx, fiber.StatusUnauthorized, Unauthorized, c.message)
+		})
+
+		req := httptest.NewRequest(fiber.MethodGet, "/users?page=1", nil)
+		req.Header.Set(fiber.HeaderAccept, c.accept)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+
+		if got := resp.Header.Get(fiber.HeaderContentType); got != c.contentType {
+			t.Fatalf("%s: Content-Type = %q, want %q", c.desc, got, c.contentType)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: decode body: %v", c.desc, err)
+		}
+		if !reflect.DeepEqual(body, c.body) {
+			t.Fatalf("%s: body = %v, want %v", c.desc, body, c.body)
+		}
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	cause := fmt.Errorf("select user: %w", sql.ErrNoRows)
+	err := fmt.Errorf("get user: %w", Wrap(cause, fiber.StatusNotFound, NotFound, "User not found.").WithDetail("id", 7))
+
+	if !Is(err, NotFound) || Is(err, Forbidden) {
+		t.Fatalf("Is(err, code) does not match the code of %v", err)
+	}
+	if !Is(err, sql.ErrNoRows) {
+		t.Fatalf("Is(err, cause) does not match the cause of %v", err)
+	}
+
+	var apiErr *APIError
+	if !As(err, &apiErr) {
+		t.Fatalf("As(err, *APIError) = false")
+	}
+	if apiErr.Status != fiber.StatusNotFound || apiErr.Details["id"] != 7 {
+		t.Fatalf("As(err, *APIError) = %+v", apiErr)
+	}
+	if got, want := err.Error(), "get user: notFound: User not found.: select user: sql: no rows in result set"; got != want {
+		t.Fatalf("Error() = %q, want %q", got, want)
+	}
+}
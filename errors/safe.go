@@ -0,0 +1,33 @@
+package errors
+
+// safeError marks an error as safe to expose to the client.
+type safeError struct {
+	err error
+}
+
+// Safe marks the error as safe to expose to the client,
+// so the ErrorHandler returns its message in production as well.
+func Safe(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &safeError{err: err}
+}
+
+// IsSafe reports whether any error in err's tree is marked safe to expose to the client.
+func IsSafe(err error) bool {
+	var safe *safeError
+
+	return As(err, &safe)
+}
+
+// Error returns the message of the marked error.
+func (e *safeError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the marked error.
+func (e *safeError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,44 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// stackDepth is the maximum number of frames recorded in a stack trace.
+const stackDepth = 32
+
+// stackTracer is implemented by errors that record the stack trace where they were created.
+type stackTracer interface {
+	stackTrace() []uintptr
+}
+
+// Stack returns the stack trace recorded by the first error in err's tree that has one,
+// or an empty string when there is none.
+func Stack(err error) string {
+	var tracer stackTracer
+	if !As(err, &tracer) || len(tracer.stackTrace()) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	frames := runtime.CallersFrames(tracer.stackTrace())
+	for {
+		frame, more := frames.Next()
+		_, _ = fmt.Fprintf(&builder, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+
+	return builder.String()
+}
+
+// callers records the stack trace of the caller of the function that calls it.
+func callers() []uintptr {
+	pcs := make([]uintptr, stackDepth)
+	n := runtime.Callers(3, pcs)
+
+	return pcs[:n]
+}
@@ -0,0 +1,88 @@
+package errors
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Define the Postgres SQLSTATE codes that are translated.
+const (
+	pgUniqueViolation      = "23505"
+	pgForeignKeyViolation  = "23503"
+	pgNotNullViolation     = "23502"
+	pgSerializationFailure = "40001"
+	pgQueryCanceled        = "57014"
+)
+
+// Translate converts GORM and Postgres errors into an *APIError with the matching status and code.
+// The constraint, table and column of a Postgres error are added to the details.
+// A bare Code, e.g. return errors.NotFound, gets the registered status and message of the code, see FromCode.
+// Other errors, and errors that already contain an *APIError, are returned unchanged.
+func Translate(err error) error {
+	var apiErr *APIError
+	if err == nil || As(err, &apiErr) {
+		return err
+	}
+
+	var code Code
+	if As(err, &code) {
+		apiErr = FromCode(code)
+		apiErr.Cause = err
+
+		return apiErr
+	}
+
+	var pgErr *pgconn.PgError
+	if As(err, &pgErr) {
+		return translatePgError(err, pgErr)
+	}
+
+	switch {
+	case Is(err, gorm.ErrRecordNotFound):
+		return Wrap(err, fiber.StatusNotFound, NotFound, "Resource not found.")
+	case Is(err, gorm.ErrDuplicatedKey):
+		return Wrap(err, fiber.StatusConflict, Conflict, "Resource already exists.")
+	case Is(err, gorm.ErrForeignKeyViolated):
+		return Wrap(err, fiber.StatusConflict, Conflict, "Resource references or is referenced by another resource.")
+	}
+
+	return err
+}
+
+// translatePgError converts a Postgres error by its SQLSTATE code.
+func translatePgError(err error, pgErr *pgconn.PgError) error {
+	var apiErr *APIError
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		apiErr = Wrap(err, fiber.StatusConflict, Conflict, "Resource already exists.")
+	case pgForeignKeyViolation:
+		apiErr = Wrap(err, fiber.StatusConflict, Conflict, "Resource references or is referenced by another resource.")
+	case pgNotNullViolation:
+		apiErr = Wrap(err, fiber.StatusBadRequest, MissingRequiredParam, "Required field is missing.")
+	case pgSerializationFailure:
+		apiErr = Wrap(err, fiber.StatusConflict, Conflict, "Resource was changed concurrently, please retry.").
+			WithDetail("retryable", true)
+	case pgQueryCanceled:
+		apiErr = Wrap(err, fiber.StatusGatewayTimeout, Timeout, "Request took too long to complete.")
+	default:
+		return err
+	}
+
+	details := map[string]interface{}{}
+	if pgErr.ConstraintName != "" {
+		details["constraint"] = pgErr.ConstraintName
+	}
+	if pgErr.TableName != "" {
+		details["table"] = pgErr.TableName
+	}
+	if pgErr.ColumnName != "" {
+		details["column"] = pgErr.ColumnName
+	}
+	if len(details) > 0 {
+		apiErr = apiErr.WithDetails(details)
+	}
+
+	return apiErr
+}
@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestTranslate(t *testing.T) {
+	cases := []struct {
+		in      error
+		status  int
+		code    Code
+		details map[string]interface{}
+		desc    string
+	}{
+		{in: fmt.Errorf("find user: %w", gorm.ErrRecordNotFound), status: fiber.StatusNotFound, code: NotFound, desc: "record not found"},
+		{in: gorm.ErrDuplicatedKey, status: fiber.StatusConflict, code: Conflict, desc: "gorm duplicated key"},
+		{
+			in:      &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key", TableName: "users"},
+			status:  fiber.StatusConflict,
+			code:    Conflict,
+			details: map[string]interface{}{"constraint": "users_email_key", "table": "users"},
+			desc:    "unique violation",
+		},
+		{
+			in:      &pgconn.PgError{Code: "23503", ConstraintName: "orders_user_id_fkey"},
+			status:  fiber.StatusConflict,
+			code:    Conflict,
+			details: map[string]interface{}{"constraint": "orders_user_id_fkey"},
+			desc:    "foreign key violation",
+		},
+		{
+			in:      fmt.Errorf("create user: %w", &pgconn.PgError{Code: "23502", TableName: "users", ColumnName: "email"}),
+			status:  fiber.StatusBadRequest,
+			code:    MissingRequiredParam,
+			details: map[string]interface{}{"table": "users", "column": "email"},
+			desc:    "not null violation",
+		},
+		{
+			in:      &pgconn.PgError{Code: "40001"},
+			status:  fiber.StatusConflict,
+			code:    Conflict,
+			details: map[string]interface{}{"retryable": true},
+			desc:    "serialization failure",
+		},
+		{in: &pgconn.PgError{Code: "57014"}, status: fiber.StatusGatewayTimeout, code: Timeout, desc: "query canceled"},
+		{in: NotFound, status: fiber.StatusNotFound, code: NotFound, desc: "bare code"},
+		{in: fmt.Errorf("load order: %w", Conflict), status: fiber.StatusConflict, code: Conflict, desc: "wrapped code"},
+	}
+
+	for _, c := range cases {
+		var apiErr *APIError
+		if !As(Translate(c.in), &apiErr) {
+			t.Fatalf("%s: Translate(%v) is not an *APIError", c.desc, c.in)
+		}
+		if apiErr.Status != c.status || apiErr.Code != c.code || !reflect.DeepEqual(apiErr.Details, c.details) {
+			t.Fatalf("%s: Translate(%v) = %d %s %v, want %d %s %v", c.desc, c.in, apiErr.Status, apiErr.Code, apiErr.Details, c.status, c.code, c.details)
+		}
+		if !Is(apiErr, c.in) {
+			t.Fatalf("%s: Translate(%v) does not wrap the original error", c.desc, c.in)
+		}
+	}
+
+	unchanged := []error{
+		nil,
+		fmt.Errorf("plain error"),
+		&pgconn.PgError{Code: "42P01"},
+		New(fiber.StatusForbidden, Forbidden, "No access."),
+	}
+	for _, err := range unchanged {
+		if got := Translate(err); got != err {
+			t.Fatalf("Translate(%v) = %v, want unchanged", err, got)
+		}
+	}
+}
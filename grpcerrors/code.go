@@ -0,0 +1,152 @@
+package grpcerrors
+
+import (
+	"sync"
+
+	errorsutil "github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc/codes"
+)
+
+// statusClientClosedRequest is the HTTP status of a request that was canceled by the client.
+const statusClientClosedRequest = 499
+
+// grpcCodes holds the gRPC code per error code.
+var grpcCodes = struct {
+	sync.RWMutex
+	codes map[errorsutil.Code]codes.Code
+}{codes: map[errorsutil.Code]codes.Code{
+	errorsutil.NotFound:             codes.NotFound,
+	errorsutil.Unauthorized:         codes.Unauthenticated,
+	errorsutil.InternalServerError:  codes.Internal,
+	errorsutil.BodyParse:            codes.InvalidArgument,
+	errorsutil.Validator:            codes.InvalidArgument,
+	errorsutil.QueryError:           codes.Internal,
+	errorsutil.CacheError:           codes.Unavailable,
+	errorsutil.Forbidden:            codes.PermissionDenied,
+	errorsutil.MissingRequiredParam: codes.InvalidArgument,
+	errorsutil.InvalidParam:         codes.InvalidArgument,
+	errorsutil.OutOfSync:            codes.FailedPrecondition,
+	errorsutil.Conflict:             codes.AlreadyExists,
+	errorsutil.Timeout:              codes.DeadlineExceeded,
+	errorsutil.TooManyRequests:      codes.ResourceExhausted,
+}}
+
+// RegisterCode sets the gRPC code of the error code, overriding the code derived from its HTTP status.
+func RegisterCode(code errorsutil.Code, grpcCode codes.Code) {
+	grpcCodes.Lock()
+	defer grpcCodes.Unlock()
+
+	grpcCodes.codes[code] = grpcCode
+}
+
+// GRPCCode returns the gRPC code of the error code.
+// Codes without a gRPC code, like the codes services add with errors.Register,
+// get the gRPC code of their registered HTTP status. Unknown codes return codes.Unknown.
+func GRPCCode(code errorsutil.Code) codes.Code {
+	grpcCodes.RLock()
+	grpcCode, ok := grpcCodes.codes[code]
+	grpcCodes.RUnlock()
+	if ok {
+		return grpcCode
+	}
+
+	if info, ok := errorsutil.Lookup(code); ok {
+		return CodeForStatus(info.Status)
+	}
+
+	return codes.Unknown
+}
+
+// CodeForStatus returns the gRPC code of the HTTP status.
+func CodeForStatus(status int) codes.Code {
+	switch status {
+	case fiber.StatusBadRequest:
+		return codes.InvalidArgument
+	case fiber.StatusUnauthorized:
+		return codes.Unauthenticated
+	case fiber.StatusForbidden:
+		return codes.PermissionDenied
+	case fiber.StatusNotFound:
+		return codes.NotFound
+	case fiber.StatusConflict:
+		return codes.AlreadyExists
+	case fiber.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case fiber.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case statusClientClosedRequest:
+		return codes.Canceled
+	case fiber.StatusNotImplemented:
+		return codes.Unimplemented
+	case fiber.StatusServiceUnavailable:
+		return codes.Unavailable
+	case fiber.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	switch {
+	case status >= fiber.StatusInternalServerError:
+		return codes.Internal
+	case status >= fiber.StatusBadRequest:
+		return codes.InvalidArgument
+	default:
+		return codes.Unknown
+	}
+}
+
+// HTTPStatus returns the HTTP status of the gRPC code.
+func HTTPStatus(grpcCode codes.Code) int {
+	switch grpcCode {
+	case codes.OK:
+		return fiber.StatusOK
+	case codes.Canceled:
+		return statusClientClosedRequest
+	case codes.InvalidArgument, codes.OutOfRange:
+		return fiber.StatusBadRequest
+	case codes.Unauthenticated:
+		return fiber.StatusUnauthorized
+	case codes.PermissionDenied:
+		return fiber.StatusForbidden
+	case codes.NotFound:
+		return fiber.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return fiber.StatusConflict
+	case codes.FailedPrecondition:
+		return fiber.StatusPreconditionFailed
+	case codes.ResourceExhausted:
+		return fiber.StatusTooManyRequests
+	case codes.Unimplemented:
+		return fiber.StatusNotImplemented
+	case codes.Unavailable:
+		return fiber.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return fiber.StatusGatewayTimeout
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// Code returns the error code of the gRPC code, used for statuses without error info.
+func Code(grpcCode codes.Code) errorsutil.Code {
+	switch grpcCode {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return errorsutil.InvalidParam
+	case codes.Unauthenticated:
+		return errorsutil.Unauthorized
+	case codes.PermissionDenied:
+		return errorsutil.Forbidden
+	case codes.NotFound:
+		return errorsutil.NotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return errorsutil.Conflict
+	case codes.FailedPrecondition:
+		return errorsutil.OutOfSync
+	case codes.DeadlineExceeded:
+		return errorsutil.Timeout
+	case codes.ResourceExhausted:
+		return errorsutil.TooManyRequests
+	default:
+		return errorsutil.InternalServerError
+	}
+}
@@ -0,0 +1,135 @@
+package grpcerrors
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+
+	errorsutil "github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDKey is the metadata key of the request ID, the lowercase X-Request-ID header.
+const requestIDKey = "x-request-id"
+
+// UnaryServerInterceptor converts the errors of unary handlers into gRPC statuses, see Status.
+// Internal errors are logged with their stack trace and the request ID of the incoming metadata.
+//
+//	server := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(grpcerrors.UnaryServerInterceptor()),
+//		grpc.ChainStreamInterceptor(grpcerrors.StreamServerInterceptor()),
+//	)
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, serverError(ctx, info.FullMethod, err)
+		}
+
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor converts the errors of stream handlers into gRPC statuses, see UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return serverError(ss.Context(), info.FullMethod, err)
+		}
+
+		return nil
+	}
+}
+
+// UnaryClientInterceptor converts the gRPC statuses of unary calls back into *APIError values, see FromError.
+//
+//	conn, err := grpc.NewClient(
+//		target,
+//		grpc.WithChainUnaryInterceptor(grpcerrors.UnaryClientInterceptor()),
+//		grpc.WithChainStreamInterceptor(grpcerrors.StreamClientInterceptor()),
+//	)
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor converts the gRPC statuses of streams back into *APIError values, see FromError.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, FromError(err)
+		}
+
+		return &clientStream{ClientStream: stream}, nil
+	}
+}
+
+// clientStream converts the errors of a client stream, see StreamClientInterceptor.
+type clientStream struct {
+	grpc.ClientStream
+}
+
+// Header returns the header metadata of the stream.
+func (s *clientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+
+	return md, FromError(err)
+}
+
+// CloseSend closes the send direction of the stream.
+func (s *clientStream) CloseSend() error {
+	return FromError(s.ClientStream.CloseSend())
+}
+
+// SendMsg sends a message on the stream.
+func (s *clientStream) SendMsg(m interface{}) error {
+	return FromError(s.ClientStream.SendMsg(m))
+}
+
+// RecvMsg receives a message from the stream.
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return FromError(s.ClientStream.RecvMsg(m))
+}
+
+// serverError converts the error of a handler into a gRPC status error and logs internal errors.
+func serverError(ctx context.Context, method string, err error) error {
+	requestID := incomingRequestID(ctx)
+	st := newStatus(err, requestID)
+
+	if HTTPStatus(st.Code()) >= fiber.StatusInternalServerError {
+		logError(ctx, method, requestID, err)
+	}
+
+	return st.Err()
+}
+
+// incomingRequestID returns the request ID of the incoming metadata, empty when not set.
+func incomingRequestID(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, requestIDKey); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// logError logs the error with its stack trace and request ID.
+// The stack trace where the error was created is used when recorded, otherwise the current stack trace.
+func logError(ctx context.Context, method, requestID string, err error) {
+	stack := errorsutil.Stack(err)
+	if stack == "" {
+		stack = string(debug.Stack())
+	}
+
+	slog.ErrorContext(
+		ctx,
+		"rpc failed",
+		slog.String("requestId", requestID),
+		slog.String("method", method),
+		slog.String("error", err.Error()),
+		slog.String("stack", stack),
+	)
+}
@@ -0,0 +1,205 @@
+package grpcerrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	errorsutil "github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/ArnoldPMolenaar/api-utils/utils"
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Domain is the domain of the error info detail that carries the error code.
+const Domain = "api-utils"
+
+// Status converts the error into a gRPC status.
+// GORM and Postgres errors and bare error codes are translated first, see errors.Translate.
+// An *APIError keeps its code and details in an ErrorInfo detail, its field errors
+// in a BadRequest detail and its request ID in a RequestInfo detail.
+// Errors that already carry a gRPC status are returned unchanged, context errors get
+// their matching code and other errors become codes.Internal, where the message is
+// replaced by a generic message in production unless the error is marked safe, see errors.Safe.
+func Status(err error) *status.Status {
+	return newStatus(err, "")
+}
+
+// newStatus converts the error into a gRPC status, see Status.
+// The request ID is used when the error does not carry its own request ID.
+func newStatus(err error, requestID string) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	err = errorsutil.Translate(err)
+
+	var apiErr *errorsutil.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.RequestID != "" {
+			requestID = apiErr.RequestID
+		}
+
+		return apiErrorStatus(apiErr, requestID)
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err)
+	}
+
+	// Only expose the message of internal errors in development or when marked safe.
+	message := errorsutil.InternalErrorMessage
+	if utils.IsDevelopment() || errorsutil.IsSafe(err) {
+		message = err.Error()
+	}
+
+	return apiErrorStatus(errorsutil.New(fiber.StatusInternalServerError, errorsutil.InternalServerError, message), requestID)
+}
+
+// apiErrorStatus creates the gRPC status of the API error with its details.
+func apiErrorStatus(apiErr *errorsutil.APIError, requestID string) *status.Status {
+	st := status.New(GRPCCode(apiErr.Code), apiErr.Message)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   string(apiErr.Code),
+		Domain:   Domain,
+		Metadata: encodeDetails(apiErr.Details),
+	}}
+
+	if len(apiErr.Errors) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, fieldErr := range apiErr.Errors {
+			field := fieldErr.Pointer
+			if field == "" {
+				field = fieldErr.Param
+			}
+
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field,
+				Description: fieldErr.Message,
+				Reason:      string(fieldErr.Code),
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	if requestID != "" {
+		details = append(details, &errdetails.RequestInfo{RequestId: requestID})
+	}
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+
+	return withDetails
+}
+
+// FromStatus converts the gRPC status into an *APIError, nil for a nil or OK status.
+// The code, details, field errors and request ID are read from the details of the status, see Status.
+// Statuses without an ErrorInfo detail of the Domain, e.g. of other services, get the error code of their gRPC code, see Code.
+// The status error is kept as cause, so status.Code still works on the returned error.
+func FromStatus(st *status.Status) *errorsutil.APIError {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	apiErr := errorsutil.Wrap(st.Err(), HTTPStatus(st.Code()), Code(st.Code()), st.Message())
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() != Domain || d.GetReason() == "" {
+				continue
+			}
+
+			apiErr.Code = errorsutil.Code(d.GetReason())
+			if info, ok := errorsutil.Lookup(apiErr.Code); ok {
+				apiErr.Status = info.Status
+			}
+			if len(d.GetMetadata()) > 0 {
+				apiErr = apiErr.WithDetails(decodeDetails(d.GetMetadata()))
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range d.GetFieldViolations() {
+				fieldErr := errorsutil.FieldError{
+					Code:    errorsutil.Code(violation.GetReason()),
+					Message: violation.GetDescription(),
+				}
+				if fieldErr.Code == "" {
+					fieldErr.Code = errorsutil.InvalidParam
+				}
+				if strings.HasPrefix(violation.GetField(), "/") {
+					fieldErr.Pointer = violation.GetField()
+				} else {
+					fieldErr.Param = violation.GetField()
+				}
+				apiErr = apiErr.WithErrors(fieldErr)
+			}
+		case *errdetails.RequestInfo:
+			apiErr.RequestID = d.GetRequestId()
+		}
+	}
+
+	return apiErr
+}
+
+// FromError converts an error that carries a gRPC status into an *APIError, see FromStatus.
+// Other errors, like io.EOF of a stream, are returned unchanged.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	if apiErr := FromStatus(st); apiErr != nil {
+		return apiErr
+	}
+
+	return err
+}
+
+// encodeDetails encodes the details as JSON values, because error info metadata only holds strings.
+func encodeDetails(details map[string]interface{}) map[string]string {
+	if len(details) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string, len(details))
+	for key, value := range details {
+		data, err := json.Marshal(value)
+		if err != nil {
+			data = []byte(fmt.Sprint(value))
+		}
+		values[key] = string(data)
+	}
+
+	return values
+}
+
+// decodeDetails decodes the JSON values of error info metadata, a value that is not JSON is kept as string.
+func decodeDetails(metadata map[string]string) map[string]interface{} {
+	values := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+			decoded = value
+		}
+		values[key] = decoded
+	}
+
+	return values
+}
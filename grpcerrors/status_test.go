@@ -0,0 +1,121 @@
+package grpcerrors
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	errorsutil "github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+func TestStatus(t *testing.T) {
+	t.Setenv("STAGE_STATUS", "prod")
+
+	cases := []struct {
+		err     error
+		code    codes.Code
+		message string
+		desc    string
+	}{
+		{
+			err:     errorsutil.New(fiber.StatusForbidden, errorsutil.Forbidden, "Access is denied."),
+			code:    codes.PermissionDenied,
+			message: "Access is denied.",
+			desc:    "api error",
+		},
+		{err: gorm.ErrRecordNotFound, code: codes.NotFound, message: "Resource not found.", desc: "translated"},
+		{err: errorsutil.Forbidden, code: codes.PermissionDenied, message: "Access is denied.", desc: "bare code"},
+		{err: status.Error(codes.Unavailable, "down"), code: codes.Unavailable, message: "down", desc: "status"},
+		{err: context.DeadlineExceeded, code: codes.DeadlineExceeded, message: context.DeadlineExceeded.Error(), desc: "context"},
+		{err: errors.New("connection refused"), code: codes.Internal, message: errorsutil.InternalErrorMessage, desc: "internal"},
+	}
+
+	for _, c := range cases {
+		st := Status(c.err)
+		if st.Code() != c.code || st.Message() != c.message {
+			t.Fatalf("%s: Status() = %v %q, want %v %q", c.desc, st.Code(), st.Message(), c.code, c.message)
+		}
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	const custom errorsutil.Code = "grpcTestQuotaExceeded"
+	errorsutil.MustRegister(errorsutil.CodeInfo{Code: custom, Status: fiber.StatusTooManyRequests, Message: "Quota exceeded."})
+
+	apiErr := errorsutil.New(fiber.StatusTooManyRequests, custom, "Quota exceeded.").
+		WithDetail("limit", 10).
+		WithErrors(
+			errorsutil.FieldError{Pointer: "/items/0/name", Code: errorsutil.Validator, Message: "name is required"},
+			errorsutil.FieldError{Param: "limit", Code: errorsutil.InvalidParam, Message: "must be a positive number"},
+		)
+	apiErr.RequestID = "req-1"
+
+	st := Status(apiErr)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("Status() code = %v, want %v", st.Code(), codes.ResourceExhausted)
+	}
+
+	got := FromStatus(st)
+	if got.Status != fiber.StatusTooManyRequests || got.Code != custom || got.Message != "Quota exceeded." || got.RequestID != "req-1" {
+		t.Fatalf("FromStatus() = %+v", got)
+	}
+	if !reflect.DeepEqual(got.Details, map[string]interface{}{"limit": float64(10)}) {
+		t.Fatalf("FromStatus() details = %v", got.Details)
+	}
+	if !reflect.DeepEqual(got.Errors, apiErr.Errors) {
+		t.Fatalf("FromStatus() errors = %+v, want %+v", got.Errors, apiErr.Errors)
+	}
+	if status.Code(got) != codes.ResourceExhausted {
+		t.Fatalf("status.Code(FromStatus()) = %v, want %v", status.Code(got), codes.ResourceExhausted)
+	}
+
+	plain := FromStatus(status.New(codes.NotFound, "missing"))
+	if plain.Status != fiber.StatusNotFound || plain.Code != errorsutil.NotFound {
+		t.Fatalf("FromStatus() without details = %+v", plain)
+	}
+
+	foreign, err := status.New(codes.PermissionDenied, "denied").WithDetails(&errdetails.ErrorInfo{
+		Reason:   "IAM_PERMISSION_DENIED",
+		Domain:   "iam.example.com",
+		Metadata: map[string]string{"permission": "orders.read"},
+	})
+	if err != nil {
+		t.Fatalf("WithDetails: %v", err)
+	}
+	if got := FromStatus(foreign); got.Status != fiber.StatusForbidden || got.Code != errorsutil.Forbidden || got.Details != nil {
+		t.Fatalf("FromStatus() of a foreign domain = %+v", got)
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDKey, "req-2"))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errorsutil.New(fiber.StatusConflict, errorsutil.Conflict, "User already exists.")
+	}
+
+	_, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/users.Users/Create"}, handler)
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("server error code = %v, want %v", status.Code(err), codes.AlreadyExists)
+	}
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return err
+	}
+	clientErr := UnaryClientInterceptor()(context.Background(), "/users.Users/Create", nil, nil, nil, invoker)
+
+	var apiErr *errorsutil.APIError
+	if !errors.As(clientErr, &apiErr) {
+		t.Fatalf("client error = %v, want *APIError", clientErr)
+	}
+	if !errors.Is(clientErr, errorsutil.Conflict) || apiErr.Status != fiber.StatusConflict || apiErr.RequestID != "req-2" {
+		t.Fatalf("client error = %+v", apiErr)
+	}
+}
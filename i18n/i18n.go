@@ -0,0 +1,160 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultLanguage is used when the client accepts none of the supported languages.
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// Bundle holds the translations of a language.
+type Bundle struct {
+	// Codes are the default messages per error code.
+	// The English defaults are the messages of the error registry, see errors.Register.
+	Codes map[string]string `json:"codes"`
+	// Messages are the translations of English messages, keyed by the English message.
+	Messages map[string]string `json:"messages"`
+	// Validation are the messages per validation tag, {0} is the field and {1} the param of the tag.
+	Validation map[string]string `json:"validation"`
+}
+
+// bundles holds the bundle per language.
+var bundles = struct {
+	sync.RWMutex
+	languages map[string]*Bundle
+}{languages: map[string]*Bundle{}}
+
+// init loads the embedded bundles.
+func init() {
+	entries, err := localesFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+		data, err := localesFS.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		if err := LoadBundle(strings.TrimSuffix(entry.Name(), ".json"), data); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// LoadBundle parses the JSON bundle and merges it into the bundle of the language, see RegisterBundle.
+func LoadBundle(language string, data []byte) error {
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("error, cannot parse bundle of language '%s', %w", language, err)
+	}
+
+	RegisterBundle(language, bundle)
+
+	return nil
+}
+
+// RegisterBundle merges the translations into the bundle of the language,
+// so services can add the messages of their own error codes and validation tags.
+// Validation messages only apply to validators created after registering.
+func RegisterBundle(language string, bundle Bundle) {
+	bundles.Lock()
+	defer bundles.Unlock()
+
+	current, ok := bundles.languages[language]
+	if !ok {
+		current = &Bundle{Codes: map[string]string{}, Messages: map[string]string{}, Validation: map[string]string{}}
+		bundles.languages[language] = current
+	}
+
+	for key, value := range bundle.Codes {
+		current.Codes[key] = value
+	}
+	for key, value := range bundle.Messages {
+		current.Messages[key] = value
+	}
+	for key, value := range bundle.Validation {
+		current.Validation[key] = value
+	}
+}
+
+// Languages returns the languages that have a bundle, the default language first.
+func Languages() []string {
+	bundles.RLock()
+	defer bundles.RUnlock()
+
+	languages := []string{DefaultLanguage}
+	for language := range bundles.languages {
+		if language != DefaultLanguage {
+			languages = append(languages, language)
+		}
+	}
+
+	return languages
+}
+
+// Language returns the supported language that best matches the Accept-Language header of the request.
+func Language(c *fiber.Ctx) string {
+	if language := c.AcceptsLanguages(Languages()...); language != "" {
+		return language
+	}
+
+	return DefaultLanguage
+}
+
+// Message translates the English message of the error code to the language of the request.
+// See Translate for how the message is resolved.
+func Message(c *fiber.Ctx, code, message string) string {
+	return Translate(Language(c), code, message)
+}
+
+// Translate translates the English message of the error code to the language.
+// The message is resolved by the Messages of the bundle first, then by the Codes of the bundle
+// when the message is empty or the default English message of the code.
+// The message is returned unchanged when there is no translation.
+func Translate(language, code, message string) string {
+	bundles.RLock()
+	defer bundles.RUnlock()
+
+	bundle, ok := bundles.languages[language]
+	if !ok {
+		return message
+	}
+
+	if translated, ok := bundle.Messages[message]; ok {
+		return translated
+	}
+
+	if message == "" || message == bundles.languages[DefaultLanguage].Codes[code] {
+		if translated, ok := bundle.Codes[code]; ok {
+			return translated
+		}
+	}
+
+	return message
+}
+
+// validationMessages returns a copy of the validation messages of the language.
+func validationMessages(language string) map[string]string {
+	bundles.RLock()
+	defer bundles.RUnlock()
+
+	messages := map[string]string{}
+	if bundle, ok := bundles.languages[language]; ok {
+		for tag, message := range bundle.Validation {
+			messages[tag] = message
+		}
+	}
+
+	return messages
+}
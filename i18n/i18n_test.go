@@ -0,0 +1,113 @@
+package i18n
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestTranslate(t *testing.T) {
+	// The English default messages are registered by the errors package.
+	RegisterBundle(DefaultLanguage, Bundle{Codes: map[string]string{"forbidden": "Access is denied."}})
+
+	cases := []struct {
+		language string
+		code     string
+		message  string
+		out      string
+		desc     string
+	}{
+		{language: "nl", code: "unauthorized", message: "Machine key is invalid.", out: "Machinesleutel is ongeldig.", desc: "message"},
+		{language: "de", code: "unauthorized", message: "Token has expired.", out: "Das Token ist abgelaufen.", desc: "message of token"},
+		{language: "de", code: "forbidden", message: "Access is denied.", out: "Zugriff verweigert.", desc: "default message of code"},
+		{language: "de", code: "forbidden", message: "", out: "Zugriff verweigert.", desc: "empty message"},
+		{language: "nl", code: "forbidden", message: "Only owners can do this.", out: "Only owners can do this.", desc: "unknown message"},
+		{language: "fr", code: "forbidden", message: "Access is denied.", out: "Access is denied.", desc: "unknown language"},
+	}
+
+	for _, c := range cases {
+		if got := Translate(c.language, c.code, c.message); got != c.out {
+			t.Fatalf("%s: Translate(%q, %q, %q) = %q, want %q", c.desc, c.language, c.code, c.message, got, c.out)
+		}
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	cases := []struct {
+		header string
+		out    string
+	}{
+		{header: "", out: "en"},
+		{header: "nl-NL,nl;q=0.9,en;q=0.8", out: "nl"},
+		{header: "fr-FR, de;q=0.5", out: "de"},
+		{header: "fr-FR", out: "en"},
+	}
+
+	for _, c := range cases {
+		app := fiber.New()
+		app.Get("/", func(ctx *fiber.Ctx) error {
+			return ctx.SendString(Language(ctx))
+		})
+
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set(fiber.HeaderAcceptLanguage, c.header)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+
+		body := make([]byte, 8)
+		n, _ := resp.Body.Read(body)
+		if got := string(body[:n]); got != c.out {
+			t.Fatalf("Language(%q) = %q, want %q", c.header, got, c.out)
+		}
+	}
+}
+
+func TestRegisterValidatorTranslations(t *testing.T) {
+	type user struct {
+		Name  string `validate:"required"`
+		Email string `validate:"email"`
+	}
+
+	// Translations are registered on every new validator without conflicts.
+	for i := 0; i < 2; i++ {
+		validate := validator.New()
+		if err := RegisterValidatorTranslations(validate); err != nil {
+			t.Fatalf("RegisterValidatorTranslations() unexpected error: %v", err)
+		}
+
+		err := validate.Struct(user{Email: "invalid"})
+		translated := err.(validator.ValidationErrors).Translate(ValidatorTranslatorFor("nl"))
+		if got, want := translated["user.Name"], "Name is een verplicht veld"; got != want {
+			t.Fatalf("required = %q, want %q", got, want)
+		}
+		if got, want := translated["user.Email"], "Email moet een geldig e-mailadres zijn"; got != want {
+			t.Fatalf("email = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestValidatorBuiltInTranslations(t *testing.T) {
+	type order struct {
+		Name string   `validate:"min=3"`
+		Tags []string `validate:"max=1"`
+	}
+
+	validate := validator.New()
+	if err := RegisterValidatorTranslations(validate); err != nil {
+		t.Fatalf("RegisterValidatorTranslations() unexpected error: %v", err)
+	}
+
+	// The type-aware built-in messages of min and max are kept.
+	err := validate.Struct(order{Name: "ab", Tags: []string{"a", "b"}})
+	translated := err.(validator.ValidationErrors).Translate(ValidatorTranslatorFor("en"))
+	if got, want := translated["order.Name"], "Name must be at least 3 characters in length"; got != want {
+		t.Fatalf("min = %q, want %q", got, want)
+	}
+	if got, want := translated["order.Tags"], "Tags must contain at maximum 1 item"; got != want {
+		t.Fatalf("max = %q, want %q", got, want)
+	}
+}
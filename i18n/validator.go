@@ -0,0 +1,114 @@
+package i18n
+
+import (
+	"errors"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/nl"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	detranslations "github.com/go-playground/validator/v10/translations/de"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	nltranslations "github.com/go-playground/validator/v10/translations/nl"
+	"github.com/gofiber/fiber/v2"
+)
+
+// universalTranslator holds the translators of the languages with validation messages.
+var universalTranslator = ut.New(en.New(), en.New(), nl.New(), de.New())
+
+// defaultTranslations registers the built-in validator translations per language.
+var defaultTranslations = map[string]func(*validator.Validate, ut.Translator) error{
+	"en": entranslations.RegisterDefaultTranslations,
+	"nl": nltranslations.RegisterDefaultTranslations,
+	"de": detranslations.RegisterDefaultTranslations,
+}
+
+// translator wraps a universal translator and ignores conflicting translations,
+// so the same translations can be registered on every new validator.
+type translator struct {
+	ut.Translator
+}
+
+// RegisterValidatorTranslations registers the validation messages of the bundles on the validator.
+// The built-in validator translations of English, Dutch and German are registered first,
+// the validation messages of the bundles override them per tag.
+func RegisterValidatorTranslations(v *validator.Validate) error {
+	for language, registerDefaults := range defaultTranslations {
+		trans := ValidatorTranslatorFor(language)
+		if err := registerDefaults(v, trans); err != nil {
+			return err
+		}
+
+		for tag, message := range validationMessages(language) {
+			tag, message := tag, message
+			err := v.RegisterTranslation(
+				tag,
+				trans,
+				func(t ut.Translator) error {
+					return t.Add(tag, message, true)
+				},
+				func(t ut.Translator, fe validator.FieldError) string {
+					translated, err := t.T(fe.Tag(), fe.Field(), fe.Param())
+					if err != nil {
+						return fe.Error()
+					}
+
+					return translated
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidatorTranslator returns the translator of validation errors for the language of the request.
+func ValidatorTranslator(c *fiber.Ctx) ut.Translator {
+	return ValidatorTranslatorFor(Language(c))
+}
+
+// ValidatorTranslatorFor returns the translator of validation errors for the language,
+// the translator of the default language when there are no validation translations for it.
+func ValidatorTranslatorFor(language string) ut.Translator {
+	trans, found := universalTranslator.GetTranslator(language)
+	if !found {
+		trans, _ = universalTranslator.GetTranslator(DefaultLanguage)
+	}
+
+	return translator{Translator: trans}
+}
+
+// Add adds a translation, ignoring a conflict with an existing translation.
+func (t translator) Add(key interface{}, text string, override bool) error {
+	return ignoreConflict(t.Translator.Add(key, text, override))
+}
+
+// AddCardinal adds a cardinal plural translation, ignoring a conflict with an existing translation.
+func (t translator) AddCardinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddCardinal(key, text, rule, override))
+}
+
+// AddOrdinal adds an ordinal plural translation, ignoring a conflict with an existing translation.
+func (t translator) AddOrdinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddOrdinal(key, text, rule, override))
+}
+
+// AddRange adds a range plural translation, ignoring a conflict with an existing translation.
+func (t translator) AddRange(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddRange(key, text, rule, override))
+}
+
+// ignoreConflict drops the error of a conflicting translation.
+func ignoreConflict(err error) error {
+	var conflict *ut.ErrConflictingTranslation
+	if errors.As(err, &conflict) {
+		return nil
+	}
+
+	return err
+}
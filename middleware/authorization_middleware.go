@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Define the prefixes of role and scope requirements as constants, see Require.
+const (
+	RolePrefix  = "role:"
+	ScopePrefix = "scope:"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject is the sub claim of the token or the ID of the machine key.
+	Subject string
+	// Roles are the roles of the caller.
+	Roles []string
+	// Permissions are the permissions of the caller.
+	Permissions []string
+	// Scopes are the OAuth 2.0 scopes of the token.
+	Scopes []string
+	// Machine reports whether the caller is a service identified by a machine key.
+	Machine bool
+}
+
+// PrincipalOf returns the authenticated caller of the request from the claims of JWTProtected
+// or the machine key of MachineProtected and Signed. It reports false when the request is not authenticated.
+func PrincipalOf(c *fiber.Ctx) (Principal, bool) {
+	if claims, ok := JWTClaims(c); ok {
+		return Principal{
+			Subject:     claims.Subject,
+			Roles:       claims.Roles,
+			Permissions: claims.Permissions,
+			Scopes:      claims.Scopes(),
+		}, true
+	}
+
+	if key, ok := Machine(c); ok {
+		return Principal{
+			Subject:     key.ID,
+			Roles:       key.Roles,
+			Permissions: key.Permissions,
+			Machine:     true,
+		}, true
+	}
+
+	return Principal{}, false
+}
+
+// PermissionResolver resolves the permissions of a caller in addition to the permissions of its credentials,
+// e.g. the permissions of its roles from the database.
+type PermissionResolver interface {
+	ResolvePermissions(ctx context.Context, principal Principal) ([]string, error)
+}
+
+// PermissionResolverFunc adapts a function to a PermissionResolver.
+type PermissionResolverFunc func(ctx context.Context, principal Principal) ([]string, error)
+
+// ResolvePermissions calls the function.
+func (f PermissionResolverFunc) ResolvePermissions(ctx context.Context, principal Principal) ([]string, error) {
+	return f(ctx, principal)
+}
+
+// Authorizer creates middleware that checks the requirements of routes with a permission resolver.
+type Authorizer struct {
+	resolver PermissionResolver
+}
+
+// NewAuthorizer creates an Authorizer that resolves permissions with the resolver, nil for none.
+//
+//	authorizer := middleware.NewAuthorizer(middleware.NewCachedPermissionResolver(
+//		valkeyClient, middleware.NewPostgresPermissionResolver(db), 5*time.Minute,
+//	))
+//	app.Post("/orders", authorizer.Require("orders:write"), handler)
+func NewAuthorizer(resolver PermissionResolver) *Authorizer {
+	return &Authorizer{resolver: resolver}
+}
+
+// Require middleware checks that the caller has all requirements, see Authorizer.Require.
+// Only the permissions of the credentials are used.
+func Require(requirements ...string) func(*fiber.Ctx) error {
+	return NewAuthorizer(nil).Require(requirements...)
+}
+
+// Require middleware checks that the authenticated caller, see PrincipalOf, has all requirements.
+// A requirement is a permission like orders:write, a role like role:admin or a scope like scope:orders.read.
+// Permissions are granted by the credentials or the resolver, where orders:* grants all permissions
+// of orders and * grants all permissions.
+// If the caller is not authenticated, it returns an unauthorized error response.
+// If a requirement is missing, it returns a forbidden error response with the missing requirement.
+func (a *Authorizer) Require(requirements ...string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		principal, ok := PrincipalOf(c)
+		if !ok {
+			return errors.Response(c, fiber.StatusUnauthorized, errors.Unauthorized, "Authentication is required.")
+		}
+
+		permissions := principal.Permissions
+		if a.resolver != nil && needsPermissions(requirements) {
+			resolved, err := a.resolver.ResolvePermissions(c.UserContext(), principal)
+			if err != nil {
+				return err
+			}
+			permissions = append(slices.Clip(permissions), resolved...)
+		}
+
+		for _, requirement := range requirements {
+			if !satisfies(principal, permissions, requirement) {
+				return errors.ResponseWithDetails(
+					c,
+					fiber.StatusForbidden,
+					errors.Forbidden,
+					"A required role, scope or permission is missing.",
+					map[string]interface{}{"missing": requirement},
+				)
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// needsPermissions reports whether any requirement is a permission.
+func needsPermissions(requirements []string) bool {
+	for _, requirement := range requirements {
+		if !strings.HasPrefix(requirement, RolePrefix) && !strings.HasPrefix(requirement, ScopePrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// satisfies reports whether the caller with the permissions has the requirement.
+func satisfies(principal Principal, permissions []string, requirement string) bool {
+	if role, ok := strings.CutPrefix(requirement, RolePrefix); ok {
+		return slices.Contains(principal.Roles, role)
+	}
+	if scope, ok := strings.CutPrefix(requirement, ScopePrefix); ok {
+		return slices.Contains(principal.Scopes, scope)
+	}
+
+	for _, permission := range permissions {
+		if grants(permission, requirement) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// grants reports whether the permission grants the required permission, e.g. orders:* grants orders:write.
+func grants(permission, required string) bool {
+	if permission == "*" || permission == required {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(permission, "*")
+
+	return ok && strings.HasPrefix(required, prefix)
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestRequire(t *testing.T) {
+	resolver := PermissionResolverFunc(func(ctx context.Context, principal Principal) ([]string, error) {
+		if principal.Subject == "billing" {
+			return []string{"invoices:*"}, nil
+		}
+
+		return nil, nil
+	})
+	authorizer := NewAuthorizer(resolver)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if id := c.Get("x-caller"); id != "" {
+			c.Locals(machineLocalsKey, MachineKey{ID: id, Roles: []string{"service"}, Permissions: []string{"orders:read"}})
+		}
+
+		return c.Next()
+	})
+	app.Get("/orders", Require("orders:read", "role:service"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/orders", Require("orders:write"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/invoices", authorizer.Require("invoices:write"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	cases := []struct {
+		method  string
+		target  string
+		caller  string
+		status  int
+		missing string
+		desc    string
+	}{
+		{method: fiber.MethodGet, target: "/orders", caller: "billing", status: fiber.StatusOK, desc: "permission and role"},
+		{method: fiber.MethodPost, target: "/orders", caller: "billing", status: fiber.StatusForbidden, missing: "orders:write", desc: "missing permission"},
+		{method: fiber.MethodPost, target: "/invoices", caller: "billing", status: fiber.StatusOK, desc: "resolved wildcard permission"},
+		{method: fiber.MethodPost, target: "/invoices", caller: "shipping", status: fiber.StatusForbidden, missing: "invoices:write", desc: "not resolved"},
+		{method: fiber.MethodGet, target: "/orders", status: fiber.StatusUnauthorized, desc: "not authenticated"},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.target, nil)
+		req.Header.Set("x-caller", c.caller)
+		req.Header.Set(fiber.HeaderAcceptLanguage, "nl")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+		if resp.StatusCode != c.status {
+			t.Fatalf("%s: status = %d, want %d", c.desc, resp.StatusCode, c.status)
+		}
+
+		if c.missing != "" {
+			var body struct {
+				Message string                 `json:"message"`
+				Details map[string]interface{} `json:"details"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("%s: decode body: %v", c.desc, err)
+			}
+			if body.Details["missing"] != c.missing {
+				t.Fatalf("%s: missing = %v, want %s", c.desc, body.Details["missing"], c.missing)
+			}
+			if want := "Een vereiste rol, scope of permissie ontbreekt."; body.Message != want {
+				t.Fatalf("%s: message = %q, want %q", c.desc, body.Message, want)
+			}
+		}
+	}
+}
+
+func TestPostgresPermissionResolver(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	resolver := NewPostgresPermissionResolver(db)
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var permissions []string
+		return resolver.query(tx, []string{"admin", "support"}).Pluck(resolver.permissionColumn, &permissions)
+	})
+
+	want := `SELECT DISTINCT "permission" FROM "role_permissions" WHERE "role" IN ('admin','support')`
+	if sql != want {
+		t.Fatalf("query = %q, want %q", sql, want)
+	}
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ArnoldPMolenaar/api-utils/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultBodyContentTypes are the content types of which the body is logged by default.
+var defaultBodyContentTypes = []string{
+	fiber.MIMEApplicationJSON,
+	"application/problem+json",
+	fiber.MIMEApplicationForm,
+	"text/*",
+}
+
+// BodyLoggerConfig defines the config of the BodyLogger middleware.
+type BodyLoggerConfig struct {
+	// MaxBodySize is the maximum number of bytes of a body that is logged, 4096 by default.
+	MaxBodySize int
+	// ContentTypes are the content types of which the body is logged, JSON, form and text by default.
+	// A type ending with /* matches all subtypes.
+	ContentTypes []string
+	// SampleRate is the fraction of requests that is logged between 0 and 1, all requests by default.
+	SampleRate float64
+	// Redact are the headers and JSON or form fields that are redacted in addition to the defaults, see RedactConfig.
+	Redact RedactConfig
+	// Skip skips logging the bodies of the request when it returns true.
+	Skip func(c *fiber.Ctx) bool
+}
+
+// BodyLogger middleware logs the headers and bodies of requests and responses for debugging integrations.
+// Sensitive headers and JSON or form fields are redacted before the bodies are truncated to the size cap,
+// bodies of other content types are left out. Use it only while debugging:
+//
+//	if os.Getenv("LOG_BODIES") == "true" {
+//		app.Use(middleware.BodyLogger(middleware.BodyLoggerConfig{SampleRate: 0.1}))
+//	}
+func BodyLogger(config BodyLoggerConfig) func(*fiber.Ctx) error {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 4096
+	}
+	if len(config.ContentTypes) == 0 {
+		config.ContentTypes = defaultBodyContentTypes
+	}
+	if config.SampleRate <= 0 {
+		config.SampleRate = 1
+	}
+
+	redactor := NewRedactor(config.Redact)
+
+	return func(c *fiber.Ctx) error {
+		if config.Skip != nil && config.Skip(c) || rand.Float64() >= config.SampleRate {
+			return c.Next()
+		}
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		requestHeaders := map[string]string{}
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			requestHeaders[string(key)] = redactor.Header(string(key), string(value))
+		})
+		responseHeaders := map[string]string{}
+		c.Response().Header.VisitAll(func(key, value []byte) {
+			responseHeaders[string(key)] = redactor.Header(string(key), string(value))
+		})
+
+		requestBody := logBody(config, redactor, string(c.Request().Header.ContentType()), c.Body())
+		responseBody := logBody(config, redactor, string(c.Response().Header.ContentType()), c.Response().Body())
+
+		utils.Logger(c).Info(
+			"http body",
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.Int("status", c.Response().StatusCode()),
+			slog.Group("request", slog.Any("headers", requestHeaders), slog.String("body", requestBody)),
+			slog.Group("response", slog.Any("headers", responseHeaders), slog.String("body", responseBody)),
+		)
+
+		return nil
+	}
+}
+
+// logBody returns the redacted and truncated body, empty when the content type is not logged.
+func logBody(config BodyLoggerConfig, redactor *Redactor, contentType string, body []byte) string {
+	if len(body) == 0 || !matchesContentType(config.ContentTypes, contentType) {
+		return ""
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+
+	text := string(body)
+	switch {
+	case strings.HasSuffix(mediaType, "json"):
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+
+		var document interface{}
+		if err := decoder.Decode(&document); err != nil {
+			return "[invalid JSON omitted]"
+		}
+
+		data, err := json.Marshal(redactor.JSON(document))
+		if err != nil {
+			return "[invalid JSON omitted]"
+		}
+		text = string(data)
+	case mediaType == fiber.MIMEApplicationForm:
+		values, err := url.ParseQuery(text)
+		if err != nil {
+			return "[invalid form omitted]"
+		}
+		redactor.Form(values)
+		text = values.Encode()
+	}
+
+	if len(text) > config.MaxBodySize {
+		text = text[:config.MaxBodySize] + "...(truncated " + strconv.Itoa(len(text)-config.MaxBodySize) + " bytes)"
+	}
+
+	return text
+}
+
+// matchesContentType reports whether the content type is one of the types, where type/* matches all subtypes.
+func matchesContentType(types []string, contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	for _, t := range types {
+		if prefix, ok := strings.CutSuffix(t, "*"); ok && strings.HasPrefix(mediaType, prefix) || t == mediaType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ArnoldPMolenaar/api-utils/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestBodyLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		utils.SetLogger(c, logger)
+		return c.Next()
+	})
+	app.Use(BodyLogger(BodyLoggerConfig{MaxBodySize: 200, Redact: RedactConfig{Paths: []string{"cards.*.number"}}}))
+	app.Post("/users", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"id": 7, "accessToken": "abc", "bio": strings.Repeat("x", 300)})
+	})
+	app.Post("/files", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusCreated).Send([]byte{0x89, 0x50, 0x4e, 0x47})
+	})
+
+	cases := []struct {
+		target      string
+		contentType string
+		body        string
+		contains    []string
+		excludes    []string
+		desc        string
+	}{
+		{
+			target:      "/users",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"name":"john","Password":"hunter2","profile":{"newPassword":"hunter3"},"cards":[{"number":"4111","expiry":"12/30"}]}`,
+			contains:    []string{`\"name\":\"john\"`, `\"expiry\":\"12/30\"`, Redacted, "truncated", `"X-Machine-Key":"[REDACTED]"`},
+			excludes:    []string{"hunter2", "hunter3", "4111", "abc", "s3cr3t"},
+			desc:        "json",
+		},
+		{
+			target:      "/users",
+			contentType: fiber.MIMEApplicationForm,
+			body:        "name=john&password=hunter2",
+			contains:    []string{"name=john", "password=%5BREDACTED%5D"},
+			excludes:    []string{"hunter2"},
+			desc:        "form",
+		},
+		{
+			target:      "/files",
+			contentType: "image/png",
+			body:        "binary",
+			excludes:    []string{"binary"},
+			desc:        "content type not logged",
+		},
+	}
+
+	for _, c := range cases {
+		buffer.Reset()
+
+		req := httptest.NewRequest(fiber.MethodPost, c.target, strings.NewReader(c.body))
+		req.Header.Set(fiber.HeaderContentType, c.contentType)
+		req.Header.Set("x-machine-key", "s3cr3t")
+		if _, err := app.Test(req); err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+
+		line := buffer.String()
+		if !json.Valid(buffer.Bytes()) {
+			t.Fatalf("%s: log line is not JSON: %s", c.desc, line)
+		}
+		for _, s := range c.contains {
+			if !strings.Contains(line, s) {
+				t.Fatalf("%s: log line does not contain %s: %s", c.desc, s, line)
+			}
+		}
+		for _, s := range c.excludes {
+			if strings.Contains(line, s) {
+				t.Fatalf("%s: log line contains %s: %s", c.desc, s, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// weakPrefix is the prefix of a weak ETag.
+const weakPrefix = "W/"
+
+// ETagConfig defines the config of the ETag middleware.
+type ETagConfig struct {
+	// Weak generates weak ETags, which mark responses that are semantically equivalent, strong ETags by default.
+	Weak bool
+	// Skip skips the ETag of the request when it returns true.
+	Skip func(c *fiber.Ctx) bool
+}
+
+// ETag middleware sets the ETag header of successful GET and HEAD responses to a hash of the body,
+// unless the handler already set an ETag, e.g. with VersionETag.
+// If the If-None-Match header matches the ETag, the body is dropped and 304 Not Modified is returned.
+//
+//	app.Use(middleware.ETag(middleware.ETagConfig{}))
+func ETag(config ETagConfig) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead || config.Skip != nil && config.Skip(c) {
+			return c.Next()
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		if c.Response().StatusCode() != fiber.StatusOK {
+			return nil
+		}
+
+		etag := string(c.Response().Header.Peek(fiber.HeaderETag))
+		if etag == "" {
+			etag = BodyETag(c.Response().Body(), config.Weak)
+			c.Set(fiber.HeaderETag, etag)
+		}
+
+		if matchETag(c.Get(fiber.HeaderIfNoneMatch), etag, false) {
+			c.Context().ResetBody()
+			c.Response().Header.Del(fiber.HeaderContentType)
+
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		return nil
+	}
+}
+
+// BodyETag returns the ETag of the body, a quoted hash of its content.
+func BodyETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+
+	return FormatETag(hex.EncodeToString(sum[:16]), weak)
+}
+
+// FormatETag quotes the value as an ETag, prefixed with W/ when it is weak.
+func FormatETag(value string, weak bool) string {
+	etag := `"` + value + `"`
+	if weak {
+		return weakPrefix + etag
+	}
+
+	return etag
+}
+
+// parseETags splits a list of ETags of an If-Match or If-None-Match header.
+func parseETags(header string) []string {
+	var etags []string
+	for _, etag := range strings.Split(header, ",") {
+		if etag = strings.TrimSpace(etag); etag != "" {
+			etags = append(etags, etag)
+		}
+	}
+
+	return etags
+}
+
+// matchETag reports whether the header matches the ETag, where * matches any ETag.
+// The strong comparison of If-Match never matches weak ETags,
+// the weak comparison of If-None-Match ignores the W/ prefix.
+func matchETag(header, etag string, strong bool) bool {
+	for _, candidate := range parseETags(header) {
+		if candidate == "*" {
+			return true
+		}
+
+		if strong {
+			if !strings.HasPrefix(candidate, weakPrefix) && !strings.HasPrefix(etag, weakPrefix) && candidate == etag {
+				return true
+			}
+
+			continue
+		}
+
+		if strings.TrimPrefix(candidate, weakPrefix) == strings.TrimPrefix(etag, weakPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestETag(t *testing.T) {
+	body := `{"id":7}`
+	strong := BodyETag([]byte(body), false)
+	weak := BodyETag([]byte(body), true)
+
+	cases := []struct {
+		desc        string
+		config      ETagConfig
+		method      string
+		target      string
+		ifNoneMatch string
+		status      int
+		etag        string
+		body        string
+	}{
+		{desc: "strong", method: fiber.MethodGet, target: "/orders/7", status: fiber.StatusOK, etag: strong, body: body},
+		{desc: "weak", config: ETagConfig{Weak: true}, method: fiber.MethodGet, target: "/orders/7", status: fiber.StatusOK, etag: weak, body: body},
+		{desc: "not modified", method: fiber.MethodGet, target: "/orders/7", ifNoneMatch: strong, status: fiber.StatusNotModified, etag: strong},
+		{desc: "weak comparison", method: fiber.MethodGet, target: "/orders/7", ifNoneMatch: `"other", ` + weak, status: fiber.StatusNotModified, etag: strong},
+		{desc: "any", method: fiber.MethodGet, target: "/orders/7", ifNoneMatch: "*", status: fiber.StatusNotModified, etag: strong},
+		{desc: "modified", method: fiber.MethodGet, target: "/orders/7", ifNoneMatch: `"other"`, status: fiber.StatusOK, etag: strong, body: body},
+		{desc: "handler ETag", method: fiber.MethodGet, target: "/versions/3", ifNoneMatch: `"3"`, status: fiber.StatusNotModified, etag: `"3"`},
+		{desc: "write", method: fiber.MethodPost, target: "/orders/7", ifNoneMatch: "*", status: fiber.StatusOK, body: body},
+		{desc: "error", method: fiber.MethodGet, target: "/missing", ifNoneMatch: "*", status: fiber.StatusNotFound, body: "Cannot GET /missing"},
+		{desc: "skip", config: ETagConfig{Skip: func(c *fiber.Ctx) bool { return true }}, method: fiber.MethodGet, target: "/orders/7", status: fiber.StatusOK, body: body},
+	}
+
+	for _, c := range cases {
+		app := fiber.New()
+		app.Use(ETag(c.config))
+		app.Get("/orders/:id", func(c *fiber.Ctx) error {
+			return c.SendString(body)
+		})
+		app.Post("/orders/:id", func(c *fiber.Ctx) error {
+			return c.SendString(body)
+		})
+		app.Get("/versions/:version", func(c *fiber.Ctx) error {
+			c.Set(fiber.HeaderETag, VersionETag(3))
+
+			return c.SendString(body)
+		})
+
+		req := httptest.NewRequest(c.method, c.target, nil)
+		if c.ifNoneMatch != "" {
+			req.Header.Set(fiber.HeaderIfNoneMatch, c.ifNoneMatch)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("%s: read body: %v", c.desc, err)
+		}
+		if resp.StatusCode != c.status || resp.Header.Get(fiber.HeaderETag) != c.etag || string(data) != c.body {
+			t.Fatalf("%s: response = %d %q %q, want %d %q %q", c.desc, resp.StatusCode, resp.Header.Get(fiber.HeaderETag), data, c.status, c.etag, c.body)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valkey-io/valkey-go"
+)
+
+// Define the headers of idempotent requests as constants.
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength is the maximum length of an idempotency key.
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyConfig defines the config of the Idempotency middleware.
+type IdempotencyConfig struct {
+	// Client is the Valkey client, see cache.ValkeyConnection.
+	Client valkey.Client
+	// Methods are the methods that honor the Idempotency-Key header, POST by default.
+	Methods []string
+	// Required rejects requests without an Idempotency-Key header.
+	Required bool
+	// LockTTL is the maximum time a request is processed, after which a retry is processed again, 1 minute by default.
+	LockTTL time.Duration
+	// TTL is the time a response is stored for replay, 24 hours by default.
+	TTL time.Duration
+	// Scope separates the keys of callers, the subject of the caller or its IP address by default.
+	Scope func(c *fiber.Ctx) string
+	// Prefix is the prefix of the Valkey keys, idempotency by default.
+	Prefix string
+}
+
+// idempotencyRecord is the state of an idempotency key in Valkey.
+type idempotencyRecord struct {
+	Fingerprint string              `json:"fingerprint"`
+	Completed   bool                `json:"completed"`
+	Status      int                 `json:"status,omitempty"`
+	Headers     map[string][]string `json:"headers,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+}
+
+// Idempotency middleware makes retries of requests with an Idempotency-Key header safe.
+// The first request locks the key in Valkey and its final status, headers and body are stored.
+// Retries with the same key and request fingerprint (method, path and body) replay that response
+// with the Idempotent-Replayed header. A retry while the first request is still processed, or
+// a request with the same key and a different fingerprint, returns a conflict error response.
+// Responses with a 5xx, 408 or 429 status are not stored, so the request can be retried.
+//
+//	app.Post("/orders", middleware.Idempotency(middleware.IdempotencyConfig{Client: client}), handler)
+func Idempotency(config IdempotencyConfig) func(*fiber.Ctx) error {
+	if len(config.Methods) == 0 {
+		config.Methods = []string{fiber.MethodPost}
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = time.Minute
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.Scope == nil {
+		config.Scope = idempotencyScope
+	}
+	if config.Prefix == "" {
+		config.Prefix = "idempotency"
+	}
+
+	return func(c *fiber.Ctx) error {
+		if !slices.Contains(config.Methods, c.Method()) {
+			return c.Next()
+		}
+
+		idempotencyKey := c.Get(HeaderIdempotencyKey)
+		if idempotencyKey == "" {
+			if config.Required {
+				return errors.Response(c, fiber.StatusBadRequest, errors.MissingRequiredParam, "Idempotency-Key header is required.")
+			}
+
+			return c.Next()
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			return errors.Response(c, fiber.StatusBadRequest, errors.InvalidParam, "Idempotency-Key header is too long.")
+		}
+
+		key := fmt.Sprintf("%s:%s:%s", config.Prefix, config.Scope(c), idempotencyKey)
+		fingerprint := requestFingerprint(c)
+		ctx := c.UserContext()
+
+		// Lock the key, or replay the stored response when the key is already used.
+		lock, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+		err := config.Client.Do(ctx, config.Client.B().Set().Key(key).Value(string(lock)).Nx().Px(config.LockTTL).Build()).Error()
+		if valkey.IsValkeyNil(err) {
+			return replay(c, config.Client, key, fingerprint)
+		}
+		if err != nil {
+			return errors.Wrap(err, fiber.StatusInternalServerError, errors.CacheError, "Cache operation failed.")
+		}
+
+		// Create the final response, including the response of an error.
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = config.Client.Do(ctx, config.Client.B().Del().Key(key).Build()).Error()
+				return err
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError || status == fiber.StatusRequestTimeout || status == fiber.StatusTooManyRequests {
+			if err := config.Client.Do(ctx, config.Client.B().Del().Key(key).Build()).Error(); err != nil {
+				return errors.Wrap(err, fiber.StatusInternalServerError, errors.CacheError, "Cache operation failed.")
+			}
+
+			return nil
+		}
+
+		record, err := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			Headers:     responseHeaders(c),
+			Body:        c.Response().Body(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := config.Client.Do(ctx, config.Client.B().Set().Key(key).Value(string(record)).Px(config.TTL).Build()).Error(); err != nil {
+			return errors.Wrap(err, fiber.StatusInternalServerError, errors.CacheError, "Cache operation failed.")
+		}
+
+		return nil
+	}
+}
+
+// replay writes the stored response of the key, or a conflict error response.
+func replay(c *fiber.Ctx, client valkey.Client, key, fingerprint string) error {
+	data, err := client.Do(c.UserContext(), client.B().Get().Key(key).Build()).AsBytes()
+	if valkey.IsValkeyNil(err) {
+		return errors.Response(c, fiber.StatusConflict, errors.Conflict, "A request with this Idempotency-Key is being processed.")
+	}
+	if err != nil {
+		return errors.Wrap(err, fiber.StatusInternalServerError, errors.CacheError, "Cache operation failed.")
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+
+	switch {
+	case record.Fingerprint != fingerprint:
+		return errors.Response(c, fiber.StatusConflict, errors.Conflict, "Idempotency-Key is already used for a different request.")
+	case !record.Completed:
+		return errors.Response(c, fiber.StatusConflict, errors.Conflict, "A request with this Idempotency-Key is being processed.")
+	}
+
+	for name, values := range record.Headers {
+		c.Response().Header.Del(name)
+		for _, value := range values {
+			c.Response().Header.Add(name, value)
+		}
+	}
+	c.Set(HeaderIdempotentReplayed, "true")
+
+	return c.Status(record.Status).Send(record.Body)
+}
+
+// idempotencyScope identifies the caller by its subject, see PrincipalOf, or by its IP address.
+func idempotencyScope(c *fiber.Ctx) string {
+	if principal, ok := PrincipalOf(c); ok {
+		if principal.Machine {
+			return "machine:" + principal.Subject
+		}
+
+		return "user:" + principal.Subject
+	}
+
+	return KeyByIP(c)
+}
+
+// requestFingerprint hashes the method, path with query and body of the request.
+func requestFingerprint(c *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Method() + "\n" + c.OriginalURL() + "\n"))
+	hash.Write(c.Body())
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// unreplayedHeaders are the headers of a response that belong to the request and are not replayed.
+var unreplayedHeaders = []string{
+	fiber.HeaderContentLength,
+	fiber.HeaderDate,
+	fiber.HeaderSetCookie,
+	fiber.HeaderXRequestID,
+	fiber.HeaderRetryAfter,
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+	"RateLimit-Policy",
+}
+
+// responseHeaders returns the headers of the response that are replayed.
+func responseHeaders(c *fiber.Ctx) map[string][]string {
+	headers := map[string][]string{}
+	c.Response().Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		if !slices.ContainsFunc(unreplayedHeaders, func(header string) bool { return strings.EqualFold(header, name) }) {
+			headers[name] = append(headers[name], string(value))
+		}
+	})
+
+	return headers
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valkey-io/valkey-go"
+)
+
+func TestIdempotency(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := valkey.NewClient(valkey.ClientOption{InitAddress: []string{server.Addr()}, DisableCache: true})
+	if err != nil {
+		t.Fatalf("valkey.NewClient: %v", err)
+	}
+	defer client.Close()
+
+	created, throttled := 0, false
+	app := fiber.New()
+	app.Post("/orders", Idempotency(IdempotencyConfig{Client: client}), func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderXRequestID, c.Get(fiber.HeaderXRequestID))
+		if strings.Contains(string(c.Body()), "fail") {
+			return fiber.ErrServiceUnavailable
+		}
+		if strings.Contains(string(c.Body()), "throttle") && !throttled {
+			throttled = true
+			c.Set("RateLimit-Remaining", "0")
+
+			return fiber.ErrTooManyRequests
+		}
+
+		created++
+		c.Location("/orders/" + strconv.Itoa(created))
+
+		return c.Status(fiber.StatusCreated).SendString(strconv.Itoa(created))
+	})
+
+	cases := []struct {
+		key      string
+		body     string
+		status   int
+		response string
+		replayed bool
+		desc     string
+	}{
+		{key: "k1", body: `{"amount":1}`, status: fiber.StatusCreated, response: "1", desc: "first request"},
+		{key: "k1", body: `{"amount":1}`, status: fiber.StatusCreated, response: "1", replayed: true, desc: "retry"},
+		{key: "k1", body: `{"amount":2}`, status: fiber.StatusConflict, desc: "different body"},
+		{key: "k2", body: `{"amount":2}`, status: fiber.StatusCreated, response: "2", desc: "other key"},
+		{body: `{"amount":2}`, status: fiber.StatusCreated, response: "3", desc: "without key"},
+		{key: "k3", body: `fail`, status: fiber.StatusServiceUnavailable, desc: "server error"},
+		{key: "k3", body: `fail`, status: fiber.StatusServiceUnavailable, desc: "server error is not stored"},
+		{key: "k4", body: `throttle`, status: fiber.StatusTooManyRequests, desc: "too many requests"},
+		{key: "k4", body: `throttle`, status: fiber.StatusCreated, response: "4", desc: "too many requests is not stored"},
+		{key: "k4", body: `throttle`, status: fiber.StatusCreated, response: "4", replayed: true, desc: "replay keeps request headers"},
+	}
+
+	for i, c := range cases {
+		requestID := "req-" + strconv.Itoa(i)
+		req := httptest.NewRequest(fiber.MethodPost, "/orders", strings.NewReader(c.body))
+		req.Header.Set(HeaderIdempotencyKey, c.key)
+		req.Header.Set(fiber.HeaderXRequestID, requestID)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+		if resp.StatusCode != c.status {
+			t.Fatalf("%s: status = %d, want %d", c.desc, resp.StatusCode, c.status)
+		}
+		if got := resp.Header.Get(HeaderIdempotentReplayed) == "true"; got != c.replayed {
+			t.Fatalf("%s: replayed = %v, want %v", c.desc, got, c.replayed)
+		}
+		if c.replayed && (resp.Header.Get(fiber.HeaderXRequestID) != "" || resp.Header.Get("RateLimit-Remaining") != "") {
+			t.Fatalf("%s: replayed request headers %v", c.desc, resp.Header)
+		}
+
+		if c.response != "" {
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != c.response || resp.Header.Get(fiber.HeaderLocation) != "/orders/"+c.response {
+				t.Fatalf("%s: response = %q %q, want %q", c.desc, body, resp.Header.Get(fiber.HeaderLocation), c.response)
+			}
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minJWKSRefresh is the minimum time between two fetches of a JWKS document for an unknown key ID.
+const minJWKSRefresh = time.Minute
+
+// maxJWKSSize is the maximum number of bytes of a JWKS document that is read.
+const maxJWKSSize = 1 << 20
+
+// jwk is a single JSON Web Key of a JWKS document.
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// JWKS provides the verification keys of a JWKS document from a local file or HTTP URL.
+// The document is cached and fetched again after the refresh interval,
+// or earlier when a token has an unknown key ID. Failed fetches count as attempts,
+// so an unavailable source is not fetched for every token, and one fetch runs at a time.
+type JWKS struct {
+	mu          sync.RWMutex
+	fetchMu     sync.Mutex
+	source      string
+	refresh     time.Duration
+	client      *http.Client
+	keys        map[string]interface{}
+	attemptedAt time.Time
+}
+
+// NewJWKS creates a JWKS of the file path or http(s) URL that is fetched again after the refresh interval.
+func NewJWKS(source string, refresh time.Duration) *JWKS {
+	return &JWKS{source: source, refresh: refresh, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Keyfunc returns the key of the kid header of the token, see jwt.Keyfunc.
+func (s *JWKS) Keyfunc(token *jwt.Token) (interface{}, error) {
+	return s.KeyfuncContext(context.Background())(token)
+}
+
+// KeyfuncContext returns a jwt.Keyfunc that fetches the document with the context, e.g. of the request.
+func (s *JWKS) KeyfuncContext(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return s.Key(ctx, kid)
+	}
+}
+
+// Key returns the key with the ID, or the only key of the document when the ID is empty.
+func (s *JWKS) Key(ctx context.Context, kid string) (interface{}, error) {
+	if key, found, due := s.cached(kid); !due {
+		return key, s.notFound(found, kid)
+	}
+
+	// Fetch once at a time, callers that waited use the keys of the fetch they waited for.
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	key, found, due := s.cached(kid)
+	if !due {
+		return key, s.notFound(found, kid)
+	}
+
+	if err := s.fetch(ctx); err != nil {
+		// Keep using the cached keys when the document cannot be fetched.
+		if found {
+			return key, nil
+		}
+
+		return nil, err
+	}
+
+	key, found, _ = s.cached(kid)
+
+	return key, s.notFound(found, kid)
+}
+
+// cached returns the cached key with the ID and whether the document is due to be fetched.
+// A known key is fetched again after the refresh interval and an unknown key after minJWKSRefresh,
+// both since the last attempt.
+func (s *JWKS) cached(kid string) (interface{}, bool, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, found := s.lookup(kid)
+	since := time.Since(s.attemptedAt)
+	if found {
+		return key, true, since > s.refresh
+	}
+
+	return nil, false, since >= minJWKSRefresh
+}
+
+// lookup returns the cached key with the ID, the caller holds the lock.
+func (s *JWKS) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+
+	key, ok := s.keys[kid]
+
+	return key, ok
+}
+
+// notFound returns the error of an unknown key ID.
+func (s *JWKS) notFound(found bool, kid string) error {
+	if found {
+		return nil
+	}
+
+	return fmt.Errorf("key '%s' not found in JWKS", kid)
+}
+
+// fetch reads and parses the document, keys with an unsupported type are skipped.
+func (s *JWKS) fetch(ctx context.Context) error {
+	s.mu.Lock()
+	s.attemptedAt = time.Now()
+	s.mu.Unlock()
+
+	data, err := s.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("error, cannot parse JWKS, %w", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, k := range document.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+
+	return nil
+}
+
+// read reads the document from the file or URL.
+func (s *JWKS) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(s.source, "file://"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error, cannot fetch JWKS, status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+// publicKey converts the JWK into the key type of its algorithm.
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, errN := decodeBigInt(k.N)
+		e, errE := decodeBigInt(k.E)
+		if err := goerrors.Join(errN, errE); err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+
+		x, errX := decodeBigInt(k.X)
+		y, errY := decodeBigInt(k.Y)
+		if err := goerrors.Join(errX, errY); err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, goerrors.New("invalid Ed25519 key size")
+		}
+
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", k.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url encoded unsigned big-endian integer.
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
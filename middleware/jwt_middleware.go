@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	goerrors "errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// claimsLocalsKey is the key of the verified claims in c.Locals.
+const claimsLocalsKey = "jwtClaims"
+
+// defaultJWKSRefresh is the default refresh interval of a JWKS document configured in the .env file.
+const defaultJWKSRefresh = time.Hour
+
+// Claims are the verified claims of a bearer token.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Roles are the roles of the subject.
+	Roles []string `json:"roles,omitempty"`
+	// Permissions are the permissions of the subject, e.g. orders:write.
+	Permissions []string `json:"permissions,omitempty"`
+	// Scope is the space separated list of OAuth 2.0 scopes of the token.
+	Scope string `json:"scope,omitempty"`
+}
+
+// Scopes returns the OAuth 2.0 scopes of the token.
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// JWTConfig defines the config of the JWTProtected middleware.
+type JWTConfig struct {
+	// Key verifies the tokens: []byte for HS256, *rsa.PublicKey for RS256,
+	// *ecdsa.PublicKey for ES256 or ed25519.PublicKey for EdDSA. Ignored when KeySet is set.
+	Key interface{}
+	// KeySet provides the verification keys by the kid header of the tokens.
+	KeySet *JWKS
+	// Issuer is the required iss claim, not checked when empty.
+	Issuer string
+	// Audience is the required aud claim, not checked when empty.
+	Audience string
+	// Algorithms are the accepted algorithms, HS256, RS256, ES256 and EdDSA by default,
+	// or RS256, ES256 and EdDSA when KeySet is set.
+	Algorithms []string
+	// Leeway is the allowed clock skew when validating exp and nbf.
+	Leeway time.Duration
+}
+
+// EnvJWTConfig creates the JWT config from the .env file.
+// JWT_JWKS_URL is a JWKS file path or URL, refreshed after JWT_JWKS_REFRESH (1h by default),
+// otherwise JWT_SECRET is used as HS256 key. JWT_ISSUER and JWT_AUDIENCE are the required claims.
+func EnvJWTConfig() (JWTConfig, error) {
+	config := JWTConfig{
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+	}
+
+	switch {
+	case os.Getenv("JWT_JWKS_URL") != "":
+		refresh, err := time.ParseDuration(os.Getenv("JWT_JWKS_REFRESH"))
+		if err != nil {
+			refresh = defaultJWKSRefresh
+		}
+		config.KeySet = NewJWKS(os.Getenv("JWT_JWKS_URL"), refresh)
+	case os.Getenv("JWT_SECRET") != "":
+		config.Key = []byte(os.Getenv("JWT_SECRET"))
+	default:
+		return config, goerrors.New("JWT_JWKS_URL or JWT_SECRET is not configured in the .env file")
+	}
+
+	return config, nil
+}
+
+// JWTProtected middleware checks the bearer token of the Authorization header.
+// The signature, exp, nbf and the configured iss and aud claims are validated.
+// If the token is not valid, it returns an unauthorized error response.
+// Otherwise, it stores the claims in c.Locals, see JWTClaims, and calls the next handler.
+//
+//	config, err := middleware.EnvJWTConfig()
+//	app.Use(middleware.JWTProtected(config))
+func JWTProtected(config JWTConfig) func(*fiber.Ctx) error {
+	algorithms := config.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{"HS256", "RS256", "ES256", "EdDSA"}
+		if config.KeySet != nil {
+			algorithms = algorithms[1:]
+		}
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(algorithms),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(config.Leeway),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+	parser := jwt.NewParser(options...)
+
+	keyfunc := func(c *fiber.Ctx) jwt.Keyfunc {
+		if config.KeySet != nil {
+			return config.KeySet.KeyfuncContext(c.UserContext())
+		}
+
+		return func(token *jwt.Token) (interface{}, error) {
+			return config.Key, nil
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		scheme, tokenString, _ := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+		if !strings.EqualFold(scheme, "Bearer") || tokenString == "" {
+			return tokenError(c, "Token is missing.")
+		}
+
+		claims := &Claims{}
+		if _, err := parser.ParseWithClaims(tokenString, claims, keyfunc(c)); err != nil {
+			if goerrors.Is(err, jwt.ErrTokenExpired) {
+				return tokenError(c, "Token has expired.")
+			}
+
+			return tokenError(c, "Token is invalid.")
+		}
+
+		c.Locals(claimsLocalsKey, claims)
+
+		return c.Next()
+	}
+}
+
+// JWTClaims returns the verified claims of the request.
+// It reports false when the request did not pass JWTProtected.
+func JWTClaims(c *fiber.Ctx) (*Claims, bool) {
+	claims, ok := c.Locals(claimsLocalsKey).(*Claims)
+
+	return claims, ok
+}
+
+// tokenError creates the unauthorized response of an invalid bearer token.
+func tokenError(c *fiber.Ctx, message string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+
+	return errors.Response(c, fiber.StatusUnauthorized, errors.Unauthorized, message)
+}
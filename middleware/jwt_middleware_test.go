@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWTProtected(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	document := `{"keys":[{"kid":"k1","kty":"OKP","crv":"Ed25519","x":"` + base64.RawURLEncoding.EncodeToString(public) + `"}]}`
+	if err := os.WriteFile(path, []byte(document), 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+
+	claims := func(audience string, expiresAt time.Time) Claims {
+		return Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-1",
+				Issuer:    "https://auth.example.com",
+				Audience:  jwt.ClaimStrings{audience},
+				ExpiresAt: jwt.NewNumericDate(expiresAt),
+			},
+			Roles: []string{"admin"},
+		}
+	}
+	signEdDSA := func(claims Claims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(private)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+
+		return signed
+	}
+	signHS256 := func(claims Claims, secret string) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+
+		return signed
+	}
+
+	handler := func(c *fiber.Ctx) error {
+		claims, _ := JWTClaims(c)
+
+		return c.SendString(claims.Subject + ":" + claims.Roles[0])
+	}
+	jwksApp := fiber.New()
+	jwksApp.Use(JWTProtected(JWTConfig{KeySet: NewJWKS(path, time.Hour), Issuer: "https://auth.example.com", Audience: "orders"}))
+	jwksApp.Get("/", handler)
+	secretApp := fiber.New()
+	secretApp.Use(JWTProtected(JWTConfig{Key: []byte("s3cr3t"), Audience: "orders"}))
+	secretApp.Get("/", handler)
+
+	valid := claims("orders", time.Now().Add(time.Hour))
+	cases := []struct {
+		app    *fiber.App
+		token  string
+		status int
+		desc   string
+	}{
+		{app: jwksApp, token: signEdDSA(valid), status: fiber.StatusOK, desc: "jwks"},
+		{app: secretApp, token: signHS256(valid, "s3cr3t"), status: fiber.StatusOK, desc: "static key"},
+		{app: secretApp, token: signHS256(valid, "wrong"), status: fiber.StatusUnauthorized, desc: "wrong key"},
+		{app: jwksApp, token: signHS256(valid, "s3cr3t"), status: fiber.StatusUnauthorized, desc: "wrong algorithm"},
+		{app: jwksApp, token: signEdDSA(claims("billing", time.Now().Add(time.Hour))), status: fiber.StatusUnauthorized, desc: "wrong audience"},
+		{app: jwksApp, token: signEdDSA(claims("orders", time.Now().Add(-time.Hour))), status: fiber.StatusUnauthorized, desc: "expired"},
+		{app: jwksApp, status: fiber.StatusUnauthorized, desc: "missing"},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		if c.token != "" {
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+c.token)
+		}
+		resp, err := c.app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+		if resp.StatusCode != c.status {
+			t.Fatalf("%s: status = %d, want %d", c.desc, resp.StatusCode, c.status)
+		}
+
+		if c.status == fiber.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != "user-1:admin" {
+				t.Fatalf("%s: claims = %q, want user-1:admin", c.desc, body)
+			}
+		}
+	}
+}
+
+func TestJWKSFailedFetch(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	jwks := NewJWKS(server.URL, time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := jwks.Key(context.Background(), "unknown"); err == nil {
+				t.Errorf("Key() of an unavailable JWKS returned no error")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := jwks.Key(context.Background(), "other"); err == nil {
+		t.Fatalf("Key() of an unknown key returned no error")
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("fetches = %d, want 1", got)
+	}
+}
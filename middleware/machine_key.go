@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// MachineKey is a named key of a calling service.
+// Keys are rotated by adding a new key with a later ActiveFrom and setting
+// the ExpiresAt of the old key, so both are accepted while the callers switch.
+type MachineKey struct {
+	// ID identifies the calling service, e.g. billing.
+	ID string `json:"id"`
+	// Key is the secret the calling service sends in the x-machine-key header.
+	Key string `json:"key"`
+	// ActiveFrom is the time from which the key is accepted, zero for always.
+	ActiveFrom time.Time `json:"activeFrom,omitempty"`
+	// ExpiresAt is the time from which the key is no longer accepted, zero for never.
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+	// Roles are the roles of the calling service, see Require.
+	Roles []string `json:"roles,omitempty"`
+	// Permissions are the permissions of the calling service, see Require.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Active reports whether the key is accepted at the time.
+func (k MachineKey) Active(now time.Time) bool {
+	if !k.ActiveFrom.IsZero() && now.Before(k.ActiveFrom) {
+		return false
+	}
+
+	return k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt)
+}
+
+// MachineKeyStore provides the machine keys.
+type MachineKeyStore interface {
+	MachineKeys(ctx context.Context) ([]MachineKey, error)
+}
+
+// MachineKeyStoreFunc adapts a function to a MachineKeyStore.
+type MachineKeyStoreFunc func(ctx context.Context) ([]MachineKey, error)
+
+// MachineKeys calls the function.
+func (f MachineKeyStoreFunc) MachineKeys(ctx context.Context) ([]MachineKey, error) {
+	return f(ctx)
+}
+
+// EnvMachineKeyStore reads the machine keys from the .env file.
+// MACHINE_KEYS holds a JSON array of keys or a comma separated list of id:key pairs.
+// The single MACHINE_KEY is accepted with the ID default when MACHINE_KEYS is not configured.
+//
+//	MACHINE_KEYS=billing:s3cr3t,orders:an0ther
+//	MACHINE_KEYS=[{"id":"billing","key":"s3cr3t","expiresAt":"2026-01-01T00:00:00Z"}]
+func EnvMachineKeyStore() MachineKeyStore {
+	return MachineKeyStoreFunc(func(ctx context.Context) ([]MachineKey, error) {
+		if value := strings.TrimSpace(os.Getenv("MACHINE_KEYS")); value != "" {
+			return parseMachineKeys(value)
+		}
+
+		if key := os.Getenv("MACHINE_KEY"); key != "" {
+			return []MachineKey{{ID: "default", Key: key}}, nil
+		}
+
+		return nil, goerrors.New("MACHINE_KEYS is not configured in the .env file")
+	})
+}
+
+// parseMachineKeys parses a JSON array of keys or a comma separated list of id:key pairs.
+func parseMachineKeys(value string) ([]MachineKey, error) {
+	var keys []MachineKey
+
+	if strings.HasPrefix(value, "[") {
+		if err := json.Unmarshal([]byte(value), &keys); err != nil {
+			return nil, fmt.Errorf("error, cannot parse machine keys, %w", err)
+		}
+
+		return keys, validateMachineKeys(keys)
+	}
+
+	// The pairs contain the secrets, so errors report the position of a pair and never its value.
+	for i, pair := range strings.Split(value, ",") {
+		id, key, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("error, machine key %d is not in the format id:key", i+1)
+		}
+		keys = append(keys, MachineKey{ID: id, Key: key})
+	}
+
+	return keys, validateMachineKeys(keys)
+}
+
+// validateMachineKeys rejects keys with an empty ID or key, reported by their position.
+func validateMachineKeys(keys []MachineKey) error {
+	for i, key := range keys {
+		if key.ID == "" || key.Key == "" {
+			return fmt.Errorf("error, machine key %d has an empty id or key", i+1)
+		}
+	}
+
+	return nil
+}
+
+// FileMachineKeyStore reads the machine keys from a JSON file with an array of keys.
+// The file is read again when it was modified, so keys are rotated without a restart.
+type FileMachineKeyStore struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	keys    []MachineKey
+}
+
+// NewFileMachineKeyStore creates a FileMachineKeyStore that reads the file at the path.
+func NewFileMachineKeyStore(path string) *FileMachineKeyStore {
+	return &FileMachineKeyStore{path: path}
+}
+
+// MachineKeys returns the keys of the file.
+func (s *FileMachineKeyStore) MachineKeys(ctx context.Context) ([]MachineKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if s.keys != nil && info.ModTime().Equal(s.modTime) {
+		return s.keys, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []MachineKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("error, cannot parse machine keys of '%s', %w", s.path, err)
+	}
+	if err := validateMachineKeys(keys); err != nil {
+		return nil, err
+	}
+
+	s.keys, s.modTime = keys, info.ModTime()
+
+	return keys, nil
+}
+
+// ValkeyMachineKeyStore reads the machine keys from a Valkey hash with a field per key ID
+// and the key as JSON value, e.g. {"key":"s3cr3t","expiresAt":"2026-01-01T00:00:00Z"}.
+// The hash is cached on the client for the TTL, see valkey client side caching.
+type ValkeyMachineKeyStore struct {
+	client valkey.Client
+	key    string
+	ttl    time.Duration
+}
+
+// NewValkeyMachineKeyStore creates a ValkeyMachineKeyStore that reads the hash at the key.
+func NewValkeyMachineKeyStore(client valkey.Client, key string, ttl time.Duration) *ValkeyMachineKeyStore {
+	return &ValkeyMachineKeyStore{client: client, key: key, ttl: ttl}
+}
+
+// MachineKeys returns the keys of the hash.
+func (s *ValkeyMachineKeyStore) MachineKeys(ctx context.Context) ([]MachineKey, error) {
+	fields, err := s.client.DoCache(ctx, s.client.B().Hgetall().Key(s.key).Cache(), s.ttl).AsStrMap()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]MachineKey, 0, len(fields))
+	for id, value := range fields {
+		key := MachineKey{ID: id}
+		if err := json.Unmarshal([]byte(value), &key); err != nil {
+			return nil, fmt.Errorf("error, cannot parse machine key '%s', %w", id, err)
+		}
+		key.ID = id
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
@@ -1,25 +1,51 @@
 package middleware
 
 import (
+	"crypto/subtle"
+	"time"
+
 	"github.com/ArnoldPMolenaar/api-utils/errors"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
-	"os"
 )
 
+// machineLocalsKey is the key of the identified machine key in c.Locals.
+const machineLocalsKey = "machineKey"
+
 // MachineProtected middleware checks if the machine key is valid.
-// It reads the header x-machine-key and compares it with the value from the .env file.
+// It reads the header x-machine-key and compares it in constant time with the active keys of the stores,
+// by default the keys from the .env file, see EnvMachineKeyStore.
 // If the machine key is not valid, it returns an error response.
-// Otherwise, it calls the next handler.
-func MachineProtected() func(*fiber.Ctx) error {
+// Otherwise, it stores the identified key in c.Locals, see Machine, and calls the next handler.
+//
+//	app.Use(middleware.MachineProtected(middleware.NewFileMachineKeyStore("/etc/api/machine-keys.json")))
+func MachineProtected(stores ...MachineKeyStore) func(*fiber.Ctx) error {
+	if len(stores) == 0 {
+		stores = []MachineKeyStore{EnvMachineKeyStore()}
+	}
+
 	return func(c *fiber.Ctx) error {
-		machineKey := os.Getenv("MACHINE_KEY")
-		if machineKey == "" {
-			log.Fatal("MACHINE_KEY is not configured in the .env file")
+		headerKey := c.Get("x-machine-key")
+
+		var match *MachineKey
+		if headerKey != "" {
+			now := time.Now()
+			for _, store := range stores {
+				keys, err := store.MachineKeys(c.UserContext())
+				if err != nil {
+					return err
+				}
+
+				// Compare all keys, so the time does not reveal which key matched.
+				for i := range keys {
+					equal := subtle.ConstantTimeCompare([]byte(headerKey), []byte(keys[i].Key)) == 1
+					if equal && match == nil && keys[i].Active(now) {
+						match = &keys[i]
+					}
+				}
+			}
 		}
 
-		headerKey := c.Get("x-machine-key")
-		if headerKey == "" || headerKey != machineKey {
+		if match == nil {
 			return errors.Response(
 				c,
 				fiber.StatusUnauthorized,
@@ -28,6 +54,18 @@ func MachineProtected() func(*fiber.Ctx) error {
 			)
 		}
 
+		identity := *match
+		identity.Key = ""
+		c.Locals(machineLocalsKey, identity)
+
 		return c.Next()
 	}
 }
+
+// Machine returns the machine key that identified the caller of the request, without its secret.
+// It reports false when the request did not pass MachineProtected.
+func Machine(c *fiber.Ctx) (MachineKey, bool) {
+	key, ok := c.Locals(machineLocalsKey).(MachineKey)
+
+	return key, ok
+}
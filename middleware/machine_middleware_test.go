@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMachineProtected(t *testing.T) {
+	t.Setenv("MACHINE_KEY", "")
+	t.Setenv("MACHINE_KEYS", "billing:b1ll1ng,orders:0rd3rs")
+
+	expired := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	path := filepath.Join(t.TempDir(), "machine-keys.json")
+	data := `[{"id":"old","key":"0ld","expiresAt":"` + expired + `"},{"id":"next","key":"n3xt","activeFrom":"` + future + `"}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write keys: %v", err)
+	}
+
+	app := fiber.New()
+	app.Use(MachineProtected(EnvMachineKeyStore(), NewFileMachineKeyStore(path)))
+	app.Get("/", func(c *fiber.Ctx) error {
+		key, _ := Machine(c)
+		if key.Key != "" {
+			t.Fatalf("Machine() exposes the secret of %s", key.ID)
+		}
+
+		return c.SendString(key.ID)
+	})
+
+	cases := []struct {
+		key    string
+		status int
+		caller string
+		desc   string
+	}{
+		{key: "0rd3rs", status: fiber.StatusOK, caller: "orders", desc: "env key"},
+		{key: "0ld", status: fiber.StatusUnauthorized, desc: "expired key"},
+		{key: "n3xt", status: fiber.StatusUnauthorized, desc: "key not active yet"},
+		{key: "wrong", status: fiber.StatusUnauthorized, desc: "unknown key"},
+		{status: fiber.StatusUnauthorized, desc: "missing key"},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set("x-machine-key", c.key)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+		if resp.StatusCode != c.status {
+			t.Fatalf("%s: status = %d, want %d", c.desc, resp.StatusCode, c.status)
+		}
+
+		if c.caller != "" {
+			body, _ := io.ReadAll(resp.Body)
+			if got := string(body); got != c.caller {
+				t.Fatalf("%s: caller = %q, want %q", c.desc, got, c.caller)
+			}
+		}
+	}
+}
+
+func TestParseMachineKeys(t *testing.T) {
+	cases := []struct {
+		value string
+		err   string
+		desc  string
+	}{
+		{value: "billing:b1ll1ng,orders:0rd3rs", desc: "pairs"},
+		{value: `[{"id":"billing","key":"b1ll1ng"}]`, desc: "json"},
+		{value: "billing:b1ll1ng,s3cr3t", err: "error, machine key 2 is not in the format id:key", desc: "pair without id"},
+		{value: "billing:", err: "error, machine key 1 has an empty id or key", desc: "empty key"},
+		{value: ":s3cr3t", err: "error, machine key 1 has an empty id or key", desc: "empty id"},
+		{value: `[{"id":"billing"}]`, err: "error, machine key 1 has an empty id or key", desc: "json without key"},
+		{value: `[{"key":"s3cr3t"}]`, err: "error, machine key 1 has an empty id or key", desc: "json without id"},
+	}
+
+	for _, c := range cases {
+		_, err := parseMachineKeys(c.value)
+		if c.err == "" && err != nil || c.err != "" && (err == nil || err.Error() != c.err) {
+			t.Fatalf("%s: parseMachineKeys() error = %v, want %q", c.desc, err, c.err)
+		}
+	}
+}
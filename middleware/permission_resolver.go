@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valkey-io/valkey-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresPermissionResolver resolves the permissions of the roles of a caller from a Postgres table
+// with a row per role and permission, role_permissions(role, permission) by default.
+type PostgresPermissionResolver struct {
+	db               *gorm.DB
+	table            string
+	roleColumn       string
+	permissionColumn string
+}
+
+// NewPostgresPermissionResolver creates a PostgresPermissionResolver that reads role_permissions(role, permission).
+func NewPostgresPermissionResolver(db *gorm.DB) *PostgresPermissionResolver {
+	return &PostgresPermissionResolver{db: db, table: "role_permissions", roleColumn: "role", permissionColumn: "permission"}
+}
+
+// WithTable returns a copy of the resolver that reads the table with the role and permission columns.
+func (r *PostgresPermissionResolver) WithTable(table, roleColumn, permissionColumn string) *PostgresPermissionResolver {
+	return &PostgresPermissionResolver{db: r.db, table: table, roleColumn: roleColumn, permissionColumn: permissionColumn}
+}
+
+// ResolvePermissions returns the distinct permissions of the roles of the caller.
+func (r *PostgresPermissionResolver) ResolvePermissions(ctx context.Context, principal Principal) ([]string, error) {
+	if len(principal.Roles) == 0 {
+		return nil, nil
+	}
+
+	var permissions []string
+	err := r.query(r.db.WithContext(ctx), principal.Roles).Pluck(r.permissionColumn, &permissions).Error
+
+	return permissions, err
+}
+
+// query builds the query of the permissions of the roles.
+func (r *PostgresPermissionResolver) query(db *gorm.DB, roles []string) *gorm.DB {
+	return db.Table(r.table).
+		Distinct().
+		Where(clause.IN{Column: clause.Column{Name: r.roleColumn}, Values: toInterfaces(roles)})
+}
+
+// toInterfaces converts the values for a clause.
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+
+	return result
+}
+
+// CachedPermissionResolver caches the permissions of another resolver in Valkey,
+// keyed by the subject and roles of the caller.
+// The permissions are resolved again when Valkey is not available.
+type CachedPermissionResolver struct {
+	client   valkey.Client
+	resolver PermissionResolver
+	ttl      time.Duration
+}
+
+// NewCachedPermissionResolver creates a CachedPermissionResolver that caches the permissions for the TTL.
+func NewCachedPermissionResolver(client valkey.Client, resolver PermissionResolver, ttl time.Duration) *CachedPermissionResolver {
+	return &CachedPermissionResolver{client: client, resolver: resolver, ttl: ttl}
+}
+
+// ResolvePermissions returns the cached permissions, or resolves and caches them.
+func (r *CachedPermissionResolver) ResolvePermissions(ctx context.Context, principal Principal) ([]string, error) {
+	key := permissionsCacheKey(principal)
+
+	if data, err := r.client.Do(ctx, r.client.B().Get().Key(key).Build()).AsBytes(); err == nil {
+		var permissions []string
+		if json.Unmarshal(data, &permissions) == nil {
+			return permissions, nil
+		}
+	}
+
+	permissions, err := r.resolver.ResolvePermissions(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(permissions); err == nil {
+		_ = r.client.Do(ctx, r.client.B().Set().Key(key).Value(string(data)).Ex(r.ttl).Build()).Error()
+	}
+
+	return permissions, nil
+}
+
+// permissionsCacheKey creates the cache key of the permissions of the caller.
+func permissionsCacheKey(principal Principal) string {
+	roles := slices.Clone(principal.Roles)
+	slices.Sort(roles)
+
+	subject := principal.Subject
+	if principal.Machine {
+		subject = "machine:" + subject
+	}
+	hash := sha256.Sum256([]byte(subject + "\n" + strings.Join(roles, ",")))
+
+	return "permissions:" + hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// outOfSyncMessage is the message of the error when the copy of the client is stale.
+const outOfSyncMessage = "Resource is out of sync."
+
+// VersionETag returns the strong ETag of a resource version, see IfMatchVersion.
+func VersionETag(version int64) string {
+	return FormatETag(strconv.FormatInt(version, 10), false)
+}
+
+// IfMatch checks the If-Match header of the request against the current ETag of the resource.
+// It returns an outOfSync error when the header is set and none of its ETags strongly matches,
+// requests without the header are allowed.
+//
+//	if err := middleware.IfMatch(c, middleware.BodyETag(body, false)); err != nil {
+//		return err
+//	}
+func IfMatch(c *fiber.Ctx, etag string) error {
+	header := c.Get(fiber.HeaderIfMatch)
+	if header == "" || matchETag(header, etag, true) {
+		return nil
+	}
+
+	return errors.New(fiber.StatusPreconditionFailed, errors.OutOfSync, outOfSyncMessage)
+}
+
+// IfMatchVersion returns the resource version of the If-Match header, see VersionETag.
+// It returns false when the header is not set or is *. A weak ETag or a list of ETags
+// returns an outOfSync error, an ETag that is not a version returns an invalidParam error.
+func IfMatchVersion(c *fiber.Ctx) (int64, bool, error) {
+	etags := parseETags(c.Get(fiber.HeaderIfMatch))
+	switch {
+	case len(etags) == 0 || len(etags) == 1 && etags[0] == "*":
+		return 0, false, nil
+	case len(etags) > 1 || strings.HasPrefix(etags[0], weakPrefix):
+		return 0, false, errors.New(fiber.StatusPreconditionFailed, errors.OutOfSync, outOfSyncMessage)
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(etags[0], `"`), 10, 64)
+	if err != nil {
+		return 0, false, errors.New(fiber.StatusBadRequest, errors.InvalidParam, "If-Match header is not a valid version.")
+	}
+
+	return version, true, nil
+}
+
+// Versioned returns a GORM scope that updates or deletes the row only when the version column still has the version,
+// so concurrent writes cannot overwrite each other. Check the result with CheckVersioned:
+//
+//	version, ok, err := middleware.IfMatchVersion(c)
+//	if err != nil {
+//		return err
+//	}
+//	if ok {
+//		result := db.Model(&order).Scopes(middleware.Versioned("version", version)).
+//			Updates(map[string]interface{}{"status": status, "version": gorm.Expr("version + 1")})
+//		if err := middleware.CheckVersioned(result); err != nil {
+//			return err
+//		}
+//		c.Set(fiber.HeaderETag, middleware.VersionETag(version+1))
+//	}
+func Versioned(column string, version int64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Name: column}, Value: version})
+	}
+}
+
+// CheckVersioned returns the error of a failed Versioned write translated by errors.Translate,
+// e.g. a conflict error for a unique violation, or an outOfSync error when no row had the version.
+// Check that the row exists first, because a missing row is reported as out of sync as well.
+func CheckVersioned(result *gorm.DB) error {
+	if result.Error != nil {
+		return errors.Translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(fiber.StatusPreconditionFailed, errors.OutOfSync, outOfSyncMessage)
+	}
+
+	return nil
+}
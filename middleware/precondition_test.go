@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	cases := []struct {
+		desc    string
+		ifMatch string
+		version int64
+		ok      bool
+		code    errors.Code
+	}{
+		{desc: "no header"},
+		{desc: "any", ifMatch: "*"},
+		{desc: "version", ifMatch: VersionETag(3), version: 3, ok: true},
+		{desc: "weak", ifMatch: `W/"3"`, code: errors.OutOfSync},
+		{desc: "list", ifMatch: `"3", "4"`, code: errors.OutOfSync},
+		{desc: "invalid", ifMatch: `"abc"`, code: errors.InvalidParam},
+	}
+
+	for _, c := range cases {
+		app := fiber.New()
+		app.Put("/orders/:id", func(ctx *fiber.Ctx) error {
+			version, ok, err := IfMatchVersion(ctx)
+			if version != c.version || ok != c.ok {
+				t.Fatalf("%s: IfMatchVersion = %d %t, want %d %t", c.desc, version, ok, c.version, c.ok)
+			}
+			assertCode(t, c.desc, err, c.code)
+
+			return nil
+		})
+
+		req := httptest.NewRequest(fiber.MethodPut, "/orders/7", nil)
+		if c.ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, c.ifMatch)
+		}
+		if _, err := app.Test(req); err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	etag := BodyETag([]byte(`{"id":7}`), false)
+
+	cases := []struct {
+		desc    string
+		etag    string
+		ifMatch string
+		code    errors.Code
+	}{
+		{desc: "no header", etag: etag},
+		{desc: "match", etag: etag, ifMatch: `"other", ` + etag},
+		{desc: "any", etag: etag, ifMatch: "*"},
+		{desc: "stale", etag: etag, ifMatch: `"other"`, code: errors.OutOfSync},
+		{desc: "weak", etag: etag, ifMatch: "W/" + etag, code: errors.OutOfSync},
+	}
+
+	for _, c := range cases {
+		app := fiber.New()
+		app.Put("/orders/:id", func(ctx *fiber.Ctx) error {
+			assertCode(t, c.desc, IfMatch(ctx, c.etag), c.code)
+
+			return nil
+		})
+
+		req := httptest.NewRequest(fiber.MethodPut, "/orders/7", nil)
+		if c.ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, c.ifMatch)
+		}
+		if _, err := app.Test(req); err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+	}
+}
+
+func TestVersioned(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Table("orders").Where("id = ?", 7).Scopes(Versioned("version", 3)).
+			Updates(map[string]interface{}{"status": "paid", "version": gorm.Expr("version + 1")})
+	})
+
+	want := `UPDATE "orders" SET "status"='paid',"version"=version + 1 WHERE id = 7 AND "version" = 3`
+	if sql != want {
+		t.Fatalf("query = %q, want %q", sql, want)
+	}
+
+	assertCode(t, "stale", CheckVersioned(&gorm.DB{Statement: &gorm.Statement{}}), errors.OutOfSync)
+	assertCode(t, "updated", CheckVersioned(&gorm.DB{RowsAffected: 1}), "")
+	assertCode(t, "duplicate", CheckVersioned(&gorm.DB{Error: gorm.ErrDuplicatedKey}), errors.Conflict)
+	if err := CheckVersioned(&gorm.DB{Error: gorm.ErrInvalidDB}); err != gorm.ErrInvalidDB {
+		t.Fatalf("failed: err = %v, want %v", err, gorm.ErrInvalidDB)
+	}
+}
+
+// assertCode fails the test when the error does not have the code, an empty code expects no error.
+func assertCode(t *testing.T, desc string, err error, code errors.Code) {
+	t.Helper()
+
+	if code == "" {
+		if err != nil {
+			t.Fatalf("%s: err = %v", desc, err)
+		}
+
+		return
+	}
+
+	var apiErr *errors.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != code {
+		t.Fatalf("%s: err = %v, want %s", desc, err, code)
+	}
+}
@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/valkey-io/valkey-go"
+)
+
+// RateLimitAlgorithm is the algorithm of the RateLimit middleware.
+type RateLimitAlgorithm string
+
+// Define rate limit algorithms as constants.
+const (
+	// SlidingWindow allows Limit requests in any period of Window.
+	SlidingWindow RateLimitAlgorithm = "slidingWindow"
+	// TokenBucket allows bursts of Limit requests and refills Limit tokens per Window.
+	TokenBucket RateLimitAlgorithm = "tokenBucket"
+)
+
+// slidingWindowScript logs the requests of the window in a sorted set scored by their time in milliseconds.
+// It returns whether the request is allowed, the remaining requests and the milliseconds until a request is allowed again.
+var slidingWindowScript = valkey.NewLuaScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, reset}
+`)
+
+// tokenBucketScript keeps the tokens and the time of the last refill in a hash.
+// It returns whether the request is allowed, the remaining tokens and the milliseconds until a token is available.
+var tokenBucketScript = valkey.NewLuaScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local window = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local rate = capacity / window
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updatedAt')
+local tokens = tonumber(bucket[1]) or capacity
+local updatedAt = tonumber(bucket[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - updatedAt) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updatedAt', now)
+redis.call('PEXPIRE', KEYS[1], window)
+
+local reset = 0
+if tokens < 1 then
+	reset = math.ceil((1 - tokens) / rate)
+end
+
+return {allowed, math.floor(tokens), reset}
+`)
+
+// RateLimitConfig defines the config of the RateLimit middleware.
+type RateLimitConfig struct {
+	// Client is the Valkey client, see cache.ValkeyConnection.
+	Client valkey.Client
+	// Algorithm is the algorithm, SlidingWindow by default.
+	Algorithm RateLimitAlgorithm
+	// Limit is the number of requests per window, or the capacity of the token bucket.
+	Limit int
+	// Window is the period of the limit.
+	Window time.Duration
+	// Key identifies the caller that is limited, KeyByIP by default.
+	Key func(c *fiber.Ctx) string
+	// Prefix is the prefix of the Valkey keys, ratelimit by default.
+	Prefix string
+	// Skip skips the rate limit of the request when it returns true.
+	Skip func(c *fiber.Ctx) bool
+	// FailClosed rejects the requests when Valkey is not available, they are allowed by default.
+	FailClosed bool
+}
+
+// rateLimitResult is the outcome of a rate limit check.
+type rateLimitResult struct {
+	allowed   bool
+	remaining int64
+	reset     time.Duration
+}
+
+// RateLimit middleware limits the requests per caller across all replicas with atomic Lua scripts in Valkey.
+// The RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers are set on every response.
+// If the limit is exceeded, it returns a tooManyRequests error response with the Retry-After header.
+// It panics when the limit is not positive or the window is shorter than a millisecond,
+// the resolution of the scripts, because the limit would not be enforced.
+//
+//	app.Use(middleware.RateLimit(middleware.RateLimitConfig{
+//		Client: client,
+//		Limit:  100,
+//		Window: time.Minute,
+//		Key:    middleware.KeyByUser,
+//	}))
+func RateLimit(config RateLimitConfig) func(*fiber.Ctx) error {
+	if config.Limit <= 0 || config.Window < time.Millisecond {
+		panic(fmt.Sprintf("error, rate limit requires a positive limit and a window of at least 1ms, got %d per %s", config.Limit, config.Window))
+	}
+	if config.Algorithm == "" {
+		config.Algorithm = SlidingWindow
+	}
+	if config.Key == nil {
+		config.Key = KeyByIP
+	}
+	if config.Prefix == "" {
+		config.Prefix = "ratelimit"
+	}
+
+	script := slidingWindowScript
+	if config.Algorithm == TokenBucket {
+		script = tokenBucketScript
+	}
+
+	return rateLimit(config, func(ctx context.Context, key string) (rateLimitResult, error) {
+		args := []string{
+			strconv.FormatInt(config.Window.Milliseconds(), 10),
+			strconv.Itoa(config.Limit),
+			uuid.NewString(),
+		}
+
+		values, err := script.Exec(ctx, config.Client, []string{key}, args).AsIntSlice()
+		if err != nil {
+			return rateLimitResult{}, err
+		}
+		if len(values) != 3 {
+			return rateLimitResult{}, fmt.Errorf("error, unexpected rate limit result %v", values)
+		}
+
+		return rateLimitResult{
+			allowed:   values[0] == 1,
+			remaining: max(values[1], 0),
+			reset:     time.Duration(values[2]) * time.Millisecond,
+		}, nil
+	})
+}
+
+// rateLimit creates the middleware with the check of the rate limit, see RateLimit.
+func rateLimit(config RateLimitConfig, check func(ctx context.Context, key string) (rateLimitResult, error)) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if config.Skip != nil && config.Skip(c) {
+			return c.Next()
+		}
+
+		key := fmt.Sprintf("%s:%s:%s", config.Prefix, config.Algorithm, config.Key(c))
+		result, err := check(c.UserContext(), key)
+		if err != nil {
+			if config.FailClosed {
+				return errors.Wrap(err, fiber.StatusServiceUnavailable, errors.CacheError, "Cache operation failed.")
+			}
+
+			slog.Warn("rate limit is not checked", slog.String("key", key), slog.String("error", err.Error()))
+
+			return c.Next()
+		}
+
+		resetSeconds := strconv.Itoa(int(math.Ceil(result.reset.Seconds())))
+		c.Set("RateLimit-Limit", strconv.Itoa(config.Limit))
+		c.Set("RateLimit-Remaining", strconv.FormatInt(result.remaining, 10))
+		c.Set("RateLimit-Reset", resetSeconds)
+		c.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", config.Limit, int(math.Ceil(config.Window.Seconds()))))
+
+		if !result.allowed {
+			c.Set(fiber.HeaderRetryAfter, resetSeconds)
+
+			return errors.Response(
+				c,
+				fiber.StatusTooManyRequests,
+				errors.TooManyRequests,
+				"Too many requests, please try again later.",
+			)
+		}
+
+		return c.Next()
+	}
+}
+
+// KeyByIP identifies the caller by the IP address of the request.
+func KeyByIP(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// KeyByMachine identifies the caller by its machine key ID, see Machine, or by its IP address.
+func KeyByMachine(c *fiber.Ctx) string {
+	if key, ok := Machine(c); ok {
+		return "machine:" + key.ID
+	}
+
+	return KeyByIP(c)
+}
+
+// KeyByUser identifies the caller by the subject of its token, see JWTClaims, or by its IP address.
+func KeyByUser(c *fiber.Ctx) string {
+	if claims, ok := JWTClaims(c); ok && claims.Subject != "" {
+		return "user:" + claims.Subject
+	}
+
+	return KeyByIP(c)
+}
+
+// KeyByRoute limits every route separately, with the caller identified by the key.
+// The middleware must be added to the route itself, because c.Route() of app.Use is the route of the middleware.
+//
+//	app.Post("/orders", middleware.RateLimit(config), handler)
+func KeyByRoute(key func(c *fiber.Ctx) string) func(c *fiber.Ctx) string {
+	return func(c *fiber.Ctx) string {
+		return fmt.Sprintf("route:%s %s:%s", c.Method(), c.Route().Path, key(c))
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valkey-io/valkey-go"
+)
+
+func TestRateLimit(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := valkey.NewClient(valkey.ClientOption{InitAddress: []string{server.Addr()}, DisableCache: true})
+	if err != nil {
+		t.Fatalf("valkey.NewClient: %v", err)
+	}
+	defer client.Close()
+
+	cases := []struct {
+		algorithm RateLimitAlgorithm
+		desc      string
+	}{
+		{algorithm: SlidingWindow, desc: "sliding window"},
+		{algorithm: TokenBucket, desc: "token bucket"},
+	}
+
+	for _, c := range cases {
+		app := fiber.New()
+		app.Get("/orders", RateLimit(RateLimitConfig{
+			Client:    client,
+			Algorithm: c.algorithm,
+			Limit:     2,
+			Window:    1500 * time.Millisecond,
+			Key:       KeyByRoute(KeyByIP),
+		}), func(ctx *fiber.Ctx) error {
+			return ctx.SendStatus(fiber.StatusOK)
+		})
+
+		for i, want := range []struct {
+			status    int
+			remaining string
+		}{
+			{status: fiber.StatusOK, remaining: "1"},
+			{status: fiber.StatusOK, remaining: "0"},
+			{status: fiber.StatusTooManyRequests, remaining: "0"},
+		} {
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/orders", nil))
+			if err != nil {
+				t.Fatalf("%s: app.Test: %v", c.desc, err)
+			}
+			if resp.StatusCode != want.status {
+				t.Fatalf("%s: request %d status = %d, want %d", c.desc, i, resp.StatusCode, want.status)
+			}
+			if got := resp.Header.Get("RateLimit-Remaining"); got != want.remaining {
+				t.Fatalf("%s: request %d RateLimit-Remaining = %q, want %q", c.desc, i, got, want.remaining)
+			}
+			if got := resp.Header.Get("RateLimit-Policy"); got != "2;w=2" {
+				t.Fatalf("%s: request %d RateLimit-Policy = %q, want %q", c.desc, i, got, "2;w=2")
+			}
+			if want.status == fiber.StatusTooManyRequests && resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+				t.Fatalf("%s: Retry-After is not set", c.desc)
+			}
+		}
+	}
+}
+
+func TestRateLimitInvalidConfig(t *testing.T) {
+	cases := []struct {
+		config RateLimitConfig
+		desc   string
+	}{
+		{config: RateLimitConfig{Limit: 10}, desc: "no window"},
+		{config: RateLimitConfig{Window: time.Minute}, desc: "no limit"},
+		{config: RateLimitConfig{Limit: -1, Window: time.Minute}, desc: "negative limit"},
+		{config: RateLimitConfig{Limit: 1, Window: 500 * time.Microsecond}, desc: "sub-millisecond window"},
+	}
+
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: RateLimit() did not panic", c.desc)
+				}
+			}()
+
+			RateLimit(c.config)
+		}()
+	}
+}
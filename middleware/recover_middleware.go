@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/ArnoldPMolenaar/api-utils/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PanicReport describes a recovered panic and the request that caused it.
+type PanicReport struct {
+	RequestID string    `json:"requestId"`
+	Value     string    `json:"value"`
+	Stack     string    `json:"stack"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Route     string    `json:"route"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Time      time.Time `json:"time"`
+}
+
+// PanicHook receives the reports of recovered panics, e.g. to forward them to an error tracker.
+type PanicHook interface {
+	OnPanic(report PanicReport)
+}
+
+// PanicHookFunc adapts a function to a PanicHook.
+type PanicHookFunc func(report PanicReport)
+
+// OnPanic calls the function with the report.
+func (f PanicHookFunc) OnPanic(report PanicReport) {
+	f(report)
+}
+
+// FilePanicHook appends the reports as JSON lines to a local file.
+type FilePanicHook struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFilePanicHook creates a FilePanicHook that appends to the file at the path.
+func NewFilePanicHook(path string) *FilePanicHook {
+	return &FilePanicHook{path: path}
+}
+
+// OnPanic appends the report to the file, errors are logged.
+func (h *FilePanicHook) OnPanic(report PanicReport) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	line, err := json.Marshal(report)
+	if err == nil {
+		var file *os.File
+		if file, err = os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600); err == nil {
+			_, err = file.Write(append(line, '\n'))
+			err = errors.Join(err, file.Close())
+		}
+	}
+
+	if err != nil {
+		slog.Error("panic report not written", slog.String("path", h.path), slog.String("error", err.Error()))
+	}
+}
+
+// Recover middleware recovers panics of the next handlers.
+// It logs the panic with its stack trace and request metadata with the request-scoped logger,
+// see utils.Logger, passes the report to the hooks
+// and returns the standard internalServerError response with the request ID.
+// The panic value is only part of the response in development, see utils.IsDevelopment.
+func Recover(hooks ...PanicHook) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+
+			report := PanicReport{
+				RequestID: errors.RequestID(c),
+				Value:     fmt.Sprint(value),
+				Stack:     string(debug.Stack()),
+				Method:    c.Method(),
+				Path:      c.Path(),
+				Route:     c.Route().Path,
+				IP:        c.IP(),
+				UserAgent: c.Get(fiber.HeaderUserAgent),
+				Time:      time.Now(),
+			}
+
+			utils.Logger(c).Error(
+				"panic recovered",
+				slog.String("panic", report.Value),
+				slog.String("method", report.Method),
+				slog.String("path", report.Path),
+				slog.String("route", report.Route),
+				slog.String("ip", report.IP),
+				slog.String("stack", report.Stack),
+			)
+
+			for _, hook := range hooks {
+				callPanicHook(hook, report)
+			}
+
+			message := errors.InternalErrorMessage
+			if utils.IsDevelopment() {
+				message = report.Value
+			}
+
+			err = errors.Response(c, fiber.StatusInternalServerError, errors.InternalServerError, message)
+		}()
+
+		return c.Next()
+	}
+}
+
+// callPanicHook calls the hook and logs a panic of the hook itself.
+func callPanicHook(hook PanicHook, report PanicReport) {
+	defer func() {
+		if value := recover(); value != nil {
+			slog.Error("panic hook failed", slog.String("requestId", report.RequestID), slog.String("panic", fmt.Sprint(value)))
+		}
+	}()
+
+	hook.OnPanic(report)
+}
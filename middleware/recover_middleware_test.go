@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRecover(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Setenv("STAGE_STATUS", "prod")
+
+	var reports []PanicReport
+	hook := PanicHookFunc(func(report PanicReport) {
+		reports = append(reports, report)
+	})
+	failingHook := PanicHookFunc(func(report PanicReport) {
+		panic("tracker is down")
+	})
+
+	app := fiber.New()
+	app.Use(Recover(failingHook, hook))
+	app.Get("/orders/:id", func(c *fiber.Ctx) error {
+		panic("nil map")
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/orders/7", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "req-1")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusInternalServerError || body["code"] != string(errors.InternalServerError) ||
+		body["message"] != errors.InternalErrorMessage || body["requestId"] != "req-1" {
+		t.Fatalf("response = %d %v", resp.StatusCode, body)
+	}
+
+	if len(reports) != 1 {
+		t.Fatalf("hook received %d reports, want 1", len(reports))
+	}
+	report := reports[0]
+	if report.Value != "nil map" || report.Route != "/orders/:id" || report.RequestID != "req-1" || report.Stack == "" {
+		t.Fatalf("report = %+v", report)
+	}
+}
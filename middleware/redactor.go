@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Redacted replaces the values that are redacted.
+const Redacted = "[REDACTED]"
+
+// defaultRedactHeaders are the headers that are always redacted.
+var defaultRedactHeaders = []string{
+	fiber.HeaderAuthorization,
+	fiber.HeaderProxyAuthorization,
+	fiber.HeaderCookie,
+	fiber.HeaderSetCookie,
+	"x-machine-key",
+	HeaderSignature,
+}
+
+// defaultRedactPaths are the JSON paths that are always redacted.
+var defaultRedactPaths = []string{"**.*password*", "**.*secret*", "**.*token*"}
+
+// RedactConfig defines the sensitive values that are redacted, see NewRedactor.
+// Share one config between the BodyLogger and the audit log, so both redact the same values.
+type RedactConfig struct {
+	// Headers are the headers that are redacted in addition to
+	// Authorization, Proxy-Authorization, Cookie, Set-Cookie, x-machine-key and x-signature.
+	Headers []string
+	// Paths are the JSON paths that are redacted in addition to the password, secret and token fields,
+	// e.g. user.email or items.*.cardNumber. A * matches any key or index, ** matches any depth
+	// and a segment may contain wildcards like *password*. Keys are matched case-insensitive.
+	Paths []string
+}
+
+// Redactor redacts the sensitive headers, JSON values and form values of a RedactConfig.
+type Redactor struct {
+	headers []string
+	paths   [][]string
+}
+
+// NewRedactor creates a Redactor of the config with the default headers and paths.
+func NewRedactor(config RedactConfig) *Redactor {
+	r := &Redactor{headers: append(slices.Clone(defaultRedactHeaders), config.Headers...)}
+	for _, pattern := range append(slices.Clone(defaultRedactPaths), config.Paths...) {
+		pattern = strings.TrimPrefix(strings.ToLower(pattern), "$.")
+		r.paths = append(r.paths, strings.Split(pattern, "."))
+	}
+
+	return r
+}
+
+// Header returns the value of the header, or Redacted when the header is sensitive.
+func (r *Redactor) Header(name, value string) string {
+	for _, n := range r.headers {
+		if strings.EqualFold(n, name) {
+			return Redacted
+		}
+	}
+
+	return value
+}
+
+// JSON replaces the values at the redacted paths of a decoded JSON document in place and returns it.
+func (r *Redactor) JSON(value interface{}) interface{} {
+	return r.redactJSON(nil, value)
+}
+
+// Form replaces the values of the redacted keys of form values in place.
+func (r *Redactor) Form(values url.Values) {
+	for key := range values {
+		if r.matches([]string{strings.ToLower(key)}) {
+			values[key] = []string{Redacted}
+		}
+	}
+}
+
+// redactJSON replaces the values of the JSON document at the redacted paths.
+func (r *Redactor) redactJSON(current []string, value interface{}) interface{} {
+	if len(current) > 0 && r.matches(current) {
+		return Redacted
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = r.redactJSON(append(slices.Clip(current), strings.ToLower(key)), item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactJSON(append(slices.Clip(current), strconv.Itoa(i)), item)
+		}
+	}
+
+	return value
+}
+
+// matches reports whether the path matches any of the redacted paths.
+func (r *Redactor) matches(current []string) bool {
+	for _, pattern := range r.paths {
+		if matchSegments(pattern, current) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchSegments matches the path segments against the pattern segments, where ** matches any number of segments.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+
+	return matchSegments(pattern[1:], segments[1:])
+}
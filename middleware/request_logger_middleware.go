@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/ArnoldPMolenaar/api-utils/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestLoggerConfig defines the config of the RequestLogger middleware.
+type RequestLoggerConfig struct {
+	// Logger is the base logger of the requests, slog.Default() by default.
+	Logger *slog.Logger
+	// Skip skips the access log line of the request when it returns true, e.g. for health checks.
+	Skip func(c *fiber.Ctx) bool
+}
+
+// RequestLogger middleware accepts a valid X-Request-ID header or generates a request ID, see errors.RequestID,
+// and returns it in the X-Request-ID header and the requestId of every error response.
+// It attaches a request-scoped logger with the request ID to the request, see utils.Logger and utils.LoggerFromContext,
+// and logs one access log line per request with the method, route, status, latency and caller.
+// Errors of the next handlers are passed to the error handler of the app first, so the final status is logged.
+// Add it as the first middleware, before Recover:
+//
+//	app.Use(middleware.RequestLogger(middleware.RequestLoggerConfig{}), middleware.Recover())
+func RequestLogger(config RequestLoggerConfig) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		base := config.Logger
+		if base == nil {
+			base = slog.Default()
+		}
+		logger := base.With(slog.String("requestId", errors.RequestID(c)))
+		utils.SetLogger(c, logger)
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		if config.Skip != nil && config.Skip(c) {
+			return nil
+		}
+
+		status := c.Response().StatusCode()
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("route", c.Route().Path),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("ip", c.IP()),
+			slog.Int("bytes", len(c.Response().Body())),
+		}
+		if principal, ok := PrincipalOf(c); ok {
+			attrs = append(attrs, slog.String("caller", principal.Subject), slog.Bool("machine", principal.Machine))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(c.UserContext(), level, "request", attrs...)
+
+		return nil
+	}
+}
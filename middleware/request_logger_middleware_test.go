@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/ArnoldPMolenaar/api-utils/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+
+	app := fiber.New(fiber.Config{ErrorHandler: utils.ErrorHandler})
+	app.Use(RequestLogger(RequestLoggerConfig{Logger: logger}))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		c.Locals(machineLocalsKey, MachineKey{ID: "billing"})
+		utils.LoggerFromContext(c.UserContext()).Info("loading user")
+
+		return errors.New(fiber.StatusNotFound, errors.NotFound, "User not found.")
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/users/7", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "req-1")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound || body["requestId"] != "req-1" || resp.Header.Get(fiber.HeaderXRequestID) != "req-1" {
+		t.Fatalf("response = %d %v", resp.StatusCode, body)
+	}
+
+	var lines []map[string]interface{}
+	decoder := json.NewDecoder(&buffer)
+	for decoder.More() {
+		var line map[string]interface{}
+		if err := decoder.Decode(&line); err != nil {
+			t.Fatalf("decode log: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 || lines[0]["msg"] != "loading user" || lines[0]["requestId"] != "req-1" {
+		t.Fatalf("log lines = %v", lines)
+	}
+
+	access := lines[1]
+	want := map[string]interface{}{
+		"level":     "WARN",
+		"requestId": "req-1",
+		"method":    fiber.MethodGet,
+		"route":     "/users/:id",
+		"status":    float64(fiber.StatusNotFound),
+		"caller":    "billing",
+		"machine":   true,
+	}
+	for key, value := range want {
+		if access[key] != value {
+			t.Fatalf("access log %s = %v, want %v", key, access[key], value)
+		}
+	}
+}
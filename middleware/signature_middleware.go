@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valkey-io/valkey-go"
+)
+
+// Define the headers of a signed request as constants.
+const (
+	HeaderSignatureKeyID     = "x-signature-key-id"
+	HeaderSignatureTimestamp = "x-signature-timestamp"
+	HeaderSignatureNonce     = "x-signature-nonce"
+	HeaderSignature          = "x-signature"
+)
+
+// defaultMaxSkew is the default clock-skew window of a signed request.
+const defaultMaxSkew = 5 * time.Minute
+
+// NonceStore remembers the nonces of signed requests to reject replays.
+type NonceStore interface {
+	// Claim reports whether the nonce of the key was not used before and remembers it for the TTL.
+	Claim(ctx context.Context, keyID, nonce string, ttl time.Duration) (bool, error)
+}
+
+// ValkeyNonceStore remembers the nonces in Valkey with SET NX EX,
+// so replays are rejected across all replicas.
+//
+//	client, err := cache.ValkeyConnection()
+//	nonces := middleware.NewValkeyNonceStore(client)
+type ValkeyNonceStore struct {
+	client valkey.Client
+}
+
+// NewValkeyNonceStore creates a ValkeyNonceStore with the client.
+func NewValkeyNonceStore(client valkey.Client) *ValkeyNonceStore {
+	return &ValkeyNonceStore{client: client}
+}
+
+// Claim sets the nonce key when it does not exist yet.
+func (s *ValkeyNonceStore) Claim(ctx context.Context, keyID, nonce string, ttl time.Duration) (bool, error) {
+	key := "signature:nonce:" + keyID + ":" + nonce
+	cmd := s.client.B().Set().Key(key).Value("1").Nx().ExSeconds(int64(ttl.Seconds())).Build()
+
+	err := s.client.Do(ctx, cmd).Error()
+	if valkey.IsValkeyNil(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SignatureConfig defines the config of the Signed middleware.
+type SignatureConfig struct {
+	// Stores provide the secrets per key ID, by default the keys from the .env file, see EnvMachineKeyStore.
+	Stores []MachineKeyStore
+	// Nonces rejects replayed nonces and is required, see NewValkeyNonceStore.
+	Nonces NonceStore
+	// MaxSkew is the maximum difference between the timestamp of the request and now, 5 minutes by default.
+	MaxSkew time.Duration
+}
+
+// Signed middleware checks the HMAC-SHA256 signature of a service-to-service request, see Signer.
+// The signature covers the method, path with query, timestamp, nonce and the SHA-256 hash of the body.
+// Requests outside the clock-skew window and replayed nonces are rejected.
+// The key that signed the request is stored in c.Locals, see Machine.
+// It panics when config.Nonces is nil, because a signed request could then be replayed.
+func Signed(config SignatureConfig) func(*fiber.Ctx) error {
+	if config.Nonces == nil {
+		panic("error, signature middleware requires a nonce store")
+	}
+	if len(config.Stores) == 0 {
+		config.Stores = []MachineKeyStore{EnvMachineKeyStore()}
+	}
+	if config.MaxSkew <= 0 {
+		config.MaxSkew = defaultMaxSkew
+	}
+
+	return func(c *fiber.Ctx) error {
+		keyID := c.Get(HeaderSignatureKeyID)
+		nonce := c.Get(HeaderSignatureNonce)
+		signature, err := hex.DecodeString(c.Get(HeaderSignature))
+		if keyID == "" || nonce == "" || err != nil || len(signature) == 0 {
+			return signatureError(c, "Request signature is missing.")
+		}
+
+		seconds, err := strconv.ParseInt(c.Get(HeaderSignatureTimestamp), 10, 64)
+		if err != nil {
+			return signatureError(c, "Request signature is missing.")
+		}
+		now := time.Now()
+		timestamp := time.Unix(seconds, 0)
+		if timestamp.Before(now.Add(-config.MaxSkew)) || timestamp.After(now.Add(config.MaxSkew)) {
+			return signatureError(c, "Request signature has expired.")
+		}
+
+		payload := signaturePayload(c.Method(), c.OriginalURL(), seconds, nonce, c.Body())
+
+		var match *MachineKey
+		for _, store := range config.Stores {
+			keys, err := store.MachineKeys(c.UserContext())
+			if err != nil {
+				return err
+			}
+
+			for i := range keys {
+				if keys[i].ID == keyID && keys[i].Active(now) && match == nil &&
+					hmac.Equal(signature, sign(keys[i].Key, payload)) {
+					match = &keys[i]
+				}
+			}
+		}
+		if match == nil {
+			return signatureError(c, "Request signature is invalid.")
+		}
+
+		// Remember the nonce for the whole window in which the timestamp is accepted.
+		claimed, err := config.Nonces.Claim(c.UserContext(), keyID, nonce, 2*config.MaxSkew)
+		if err != nil {
+			return errors.Wrap(err, fiber.StatusInternalServerError, errors.CacheError, "Cache operation failed.")
+		}
+		if !claimed {
+			return signatureError(c, "Request nonce was already used.")
+		}
+
+		identity := *match
+		identity.Key = ""
+		c.Locals(machineLocalsKey, identity)
+
+		return c.Next()
+	}
+}
+
+// signatureError creates the unauthorized response of an invalid signature.
+func signatureError(c *fiber.Ctx, message string) error {
+	return errors.Response(c, fiber.StatusUnauthorized, errors.Unauthorized, message)
+}
+
+// signaturePayload creates the canonical payload of a request that is signed.
+func signaturePayload(method, path string, timestamp int64, nonce string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+
+	return []byte(strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		strconv.FormatInt(timestamp, 10),
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n"))
+}
+
+// sign creates the HMAC-SHA256 of the payload with the secret.
+func sign(secret string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// memoryNonces remembers the nonces in memory.
+type memoryNonces map[string]bool
+
+// Claim remembers the nonce of the key.
+func (n memoryNonces) Claim(ctx context.Context, keyID, nonce string, ttl time.Duration) (bool, error) {
+	if n[keyID+nonce] {
+		return false, nil
+	}
+	n[keyID+nonce] = true
+
+	return true, nil
+}
+
+func TestSigned(t *testing.T) {
+	t.Setenv("MACHINE_KEYS", "billing:b1ll1ng")
+
+	app := fiber.New()
+	app.Use(Signed(SignatureConfig{Nonces: memoryNonces{}}))
+	app.Post("/orders", func(c *fiber.Ctx) error {
+		key, _ := Machine(c)
+
+		return c.SendString(key.ID)
+	})
+
+	signed := func(signer *Signer, body string) *http.Request {
+		req := httptest.NewRequest(fiber.MethodPost, "/orders?dryRun=true", strings.NewReader(body))
+		if err := signer.Sign(req); err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+
+		return req
+	}
+
+	valid := signed(NewSigner("billing", "b1ll1ng"), `{"id":1}`)
+	replayed := httptest.NewRequest(fiber.MethodPost, "/orders?dryRun=true", strings.NewReader(`{"id":1}`))
+	replayed.Header = valid.Header.Clone()
+	tampered := httptest.NewRequest(fiber.MethodPost, "/orders?dryRun=true", strings.NewReader(`{"id":2}`))
+	tampered.Header = signed(NewSigner("billing", "b1ll1ng"), `{"id":1}`).Header
+	expired := signed(NewSigner("billing", "b1ll1ng"), `{"id":1}`)
+	expired.Header.Set(HeaderSignatureTimestamp, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+
+	cases := []struct {
+		req    *http.Request
+		status int
+		desc   string
+	}{
+		{req: valid, status: fiber.StatusOK, desc: "signed"},
+		{req: replayed, status: fiber.StatusUnauthorized, desc: "replayed nonce"},
+		{req: tampered, status: fiber.StatusUnauthorized, desc: "tampered body"},
+		{req: expired, status: fiber.StatusUnauthorized, desc: "outside clock skew"},
+		{req: signed(NewSigner("billing", "wrong"), `{"id":1}`), status: fiber.StatusUnauthorized, desc: "wrong secret"},
+		{req: httptest.NewRequest(fiber.MethodPost, "/orders", nil), status: fiber.StatusUnauthorized, desc: "unsigned"},
+	}
+
+	for _, c := range cases {
+		resp, err := app.Test(c.req)
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+		if resp.StatusCode != c.status {
+			t.Fatalf("%s: status = %d, want %d", c.desc, resp.StatusCode, c.status)
+		}
+	}
+}
+
+func TestSignedRequiresNonces(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Signed without a nonce store did not panic")
+		}
+	}()
+
+	Signed(SignatureConfig{})
+}
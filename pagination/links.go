@@ -0,0 +1,122 @@
+package pagination
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Links struct is used to return the navigation links of paginated data.
+// The links are relative to the host and keep the filter and sort params of the request.
+type Links struct {
+	Self  string `json:"self"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// WithLinks returns the model with the navigation links of the current request.
+func WithLinks(c *fiber.Ctx, model Model) Model {
+	model.Links = NewLinks(c, model)
+
+	return model
+}
+
+// NewLinks builds the navigation links of the model for the current request.
+// In offset mode the page param is replaced, in cursor mode the cursor param.
+// The last link is only available in offset mode.
+func NewLinks(c *fiber.Ctx, model Model) *Links {
+	path := c.Path()
+	query := c.Context().QueryArgs()
+	links := &Links{Self: buildLink(path, query, nil)}
+
+	if isCursorMode(model) {
+		links.First = buildLink(path, query, map[string]string{"cursor": ""})
+		if model.PrevCursor != "" {
+			links.Prev = buildLink(path, query, map[string]string{"cursor": model.PrevCursor})
+		}
+		if model.NextCursor != "" {
+			links.Next = buildLink(path, query, map[string]string{"cursor": model.NextCursor})
+		}
+
+		return links
+	}
+
+	pageLink := func(page int) string {
+		return buildLink(path, query, map[string]string{"page": strconv.Itoa(page)})
+	}
+
+	// A page past the end links back to the last page, an empty result has no previous page.
+	links.First = pageLink(1)
+	if prev := min(model.Page-1, model.PageCount); prev >= 1 {
+		links.Prev = pageLink(prev)
+	}
+	if model.Page < model.PageCount {
+		links.Next = pageLink(model.Page + 1)
+	}
+	if model.PageCount > 0 {
+		links.Last = pageLink(model.PageCount)
+	}
+
+	return links
+}
+
+// SetHeaders sets the RFC 8288 Link header and the X-Total-Count header of the model on the response.
+// The links of the model are used when set, otherwise they are built from the current request.
+// X-Total-Count is left out in cursor mode when the total is unknown.
+func SetHeaders(c *fiber.Ctx, model Model) {
+	links := model.Links
+	if links == nil {
+		links = NewLinks(c, model)
+	}
+
+	var values []string
+	for _, link := range []struct{ rel, url string }{
+		{rel: "self", url: links.Self},
+		{rel: "first", url: links.First},
+		{rel: "prev", url: links.Prev},
+		{rel: "next", url: links.Next},
+		{rel: "last", url: links.Last},
+	} {
+		if link.url != "" {
+			values = append(values, fmt.Sprintf("<%s>; rel=\"%s\"", link.url, link.rel))
+		}
+	}
+	c.Set(fiber.HeaderLink, strings.Join(values, ", "))
+
+	if !isCursorMode(model) || model.Total > 0 {
+		c.Set("X-Total-Count", strconv.Itoa(model.Total))
+	}
+}
+
+// isCursorMode reports whether the model is paginated by cursor instead of page.
+// Offset pagination always starts at page 1, so a model without page is a cursor model.
+func isCursorMode(model Model) bool {
+	return model.Page == 0
+}
+
+// buildLink builds a link to the path with the query args and the overrides applied.
+// An empty override removes the arg.
+func buildLink(path string, query *fasthttp.Args, overrides map[string]string) string {
+	args := fasthttp.AcquireArgs()
+	defer fasthttp.ReleaseArgs(args)
+
+	query.CopyTo(args)
+	for key, value := range overrides {
+		if value == "" {
+			args.Del(key)
+		} else {
+			args.Set(key, value)
+		}
+	}
+
+	if args.Len() == 0 {
+		return path
+	}
+
+	return path + "?" + args.String()
+}
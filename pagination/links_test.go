@@ -0,0 +1,96 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestNewLinks(t *testing.T) {
+	cases := []struct {
+		target string
+		model  Model
+		out    Links
+		header string
+		total  string
+		desc   string
+	}{
+		{
+			target: "/users?searchEq=role:admin&page=2",
+			model:  CreatePaginationModel(10, 2, 3, 25, nil),
+			out: Links{
+				Self:  "/users?searchEq=role%3Aadmin&page=2",
+				First: "/users?searchEq=role%3Aadmin&page=1",
+				Prev:  "/users?searchEq=role%3Aadmin&page=1",
+				Next:  "/users?searchEq=role%3Aadmin&page=3",
+				Last:  "/users?searchEq=role%3Aadmin&page=3",
+			},
+			header: `</users?searchEq=role%3Aadmin&page=2>; rel="self", </users?searchEq=role%3Aadmin&page=1>; rel="first", ` +
+				`</users?searchEq=role%3Aadmin&page=1>; rel="prev", </users?searchEq=role%3Aadmin&page=3>; rel="next", ` +
+				`</users?searchEq=role%3Aadmin&page=3>; rel="last"`,
+			total: "25",
+			desc:  "offset mode",
+		},
+		{
+			target: "/users?page=5",
+			model:  CreatePaginationModel(10, 5, 3, 25, nil),
+			out: Links{
+				Self:  "/users?page=5",
+				First: "/users?page=1",
+				Prev:  "/users?page=3",
+				Last:  "/users?page=3",
+			},
+			header: `</users?page=5>; rel="self", </users?page=1>; rel="first", </users?page=3>; rel="prev", </users?page=3>; rel="last"`,
+			total:  "25",
+			desc:   "page past the end",
+		},
+		{
+			target: "/users?page=3",
+			model:  CreatePaginationModel(10, 3, 0, 0, nil),
+			out: Links{
+				Self:  "/users?page=3",
+				First: "/users?page=1",
+			},
+			header: `</users?page=3>; rel="self", </users?page=1>; rel="first"`,
+			total:  "0",
+			desc:   "empty result",
+		},
+		{
+			target: "/users?sortBy=id:asc&cursor=abc",
+			model:  CreateCursorPaginationModel(10, "def", "", nil),
+			out: Links{
+				Self:  "/users?sortBy=id%3Aasc&cursor=abc",
+				First: "/users?sortBy=id%3Aasc",
+				Next:  "/users?sortBy=id%3Aasc&cursor=def",
+			},
+			header: `</users?sortBy=id%3Aasc&cursor=abc>; rel="self", </users?sortBy=id%3Aasc>; rel="first", ` +
+				`</users?sortBy=id%3Aasc&cursor=def>; rel="next"`,
+			desc: "cursor mode",
+		},
+	}
+
+	for _, c := range cases {
+		app := fiber.New()
+		app.Get("/users", func(ctx *fiber.Ctx) error {
+			model := WithLinks(ctx, c.model)
+			if *model.Links != c.out {
+				t.Fatalf("%s: NewLinks() = %+v, want %+v", c.desc, *model.Links, c.out)
+			}
+			SetHeaders(ctx, model)
+
+			return ctx.JSON(model)
+		})
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, c.target, nil))
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+		if got := resp.Header.Get(fiber.HeaderLink); got != c.header {
+			t.Fatalf("%s: Link = %q, want %q", c.desc, got, c.header)
+		}
+		if got := resp.Header.Get("X-Total-Count"); got != c.total {
+			t.Fatalf("%s: X-Total-Count = %q, want %q", c.desc, got, c.total)
+		}
+	}
+}
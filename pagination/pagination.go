@@ -11,28 +11,111 @@ import (
 	"gorm.io/gorm"
 )
 
+// Filter value tokens.
+const (
+	// nullToken matches NULL instead of the literal value: searchEq=deletedAt:null.
+	nullToken = "null"
+	// negationPrefix negates a filter value: searchEq=status:!archived.
+	negationPrefix = "!"
+	// escapePrefix makes a token literal: searchEq=name:\null.
+	escapePrefix = "\\"
+)
+
 // Model struct is used to return paginated data.
+// In cursor mode NextCursor and PrevCursor are set instead of the page numbers.
 type Model struct {
-	Limit     int         `json:"limit"`
-	Page      int         `json:"page"`
-	PageCount int         `json:"pageCount"`
-	Total     int         `json:"total"`
-	Result    interface{} `json:"result"`
+	Limit      int         `json:"limit"`
+	Page       int         `json:"page"`
+	PageCount  int         `json:"pageCount"`
+	Total      int         `json:"total"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	PrevCursor string      `json:"prevCursor,omitempty"`
+	Links      *Links      `json:"links,omitempty"`
+	Result     interface{} `json:"result"`
+}
+
+// Operator is the comparison a Filter applies to its column.
+type Operator string
+
+// Define filter operators as constants.
+const (
+	Equal   Operator = "eq"
+	Like    Operator = "like"
+	In      Operator = "in"
+	Between Operator = "between"
+)
+
+// Filter is a single parsed search condition.
+type Filter struct {
+	Column   string
+	Operator Operator
+	// Values holds the compared values, empty when the filter only matches NULL.
+	Values []string
+	// Null matches NULL in addition to Values, or NOT NULL when negated.
+	Null bool
+	// Negate inverts the comparison: <>, NOT ILIKE, NOT IN and IS NOT NULL.
+	Negate bool
+	// Or places the filter in the OR group that is AND-ed as a whole with the other filters.
+	Or bool
+}
+
+// SortField is a single parsed ORDER BY column.
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// ParamError describes an invalid value in a pagination query param.
+type ParamError struct {
+	Param  string
+	Column string
+	Err    error
+}
+
+// Error returns the error message including the param and column when known.
+func (e *ParamError) Error() string {
+	switch {
+	case e.Column != "":
+		return fmt.Sprintf("%s: %s: %v", e.Param, e.Column, e.Err)
+	case e.Param != "":
+		return fmt.Sprintf("%s: %v", e.Param, e.Err)
+	default:
+		return e.Err.Error()
+	}
+}
+
+// Unwrap returns the underlying error.
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// condition returns the single value condition of the filter.
+func (f Filter) condition() condition {
+	cond := condition{null: f.Null, negate: f.Negate}
+	if len(f.Values) > 0 {
+		cond.value = f.Values[0]
+	}
+
+	return cond
+}
+
+// multiCondition returns the multi value condition of the filter.
+func (f Filter) multiCondition() multiCondition {
+	return multiCondition{values: f.Values, null: f.Null, negate: f.Negate}
 }
 
 // Query builds a pagination query with the provided values
 // and checks the input columns against the allowedColumns list.
 // Returns a gorm query to be used in the function or an error.
 func Query(args *fasthttp.Args, allowedColumns map[string]bool) func(*gorm.DB) *gorm.DB {
+	filters, err := ParseFilters(args, allowedColumns)
+
 	return func(db *gorm.DB) *gorm.DB {
-		db = parseSearchLike(args.Peek("searchLike"), db, allowedColumns)
-		db = parseSearchEq(args.Peek("searchEq"), db, allowedColumns)
-		// Combine the OR groups (EqOr and LikeOr) into a single OR clause that is AND-ed with other filters
-		db = parseOr(args.Peek("searchEqOr"), args.Peek("searchLikeOr"), db, allowedColumns)
-		db = parseSearchIn(args.Peek("searchIn"), db, allowedColumns)
-		db = parseSearchBetween(args.Peek("searchBetween"), db, allowedColumns)
+		if err != nil {
+			_ = db.AddError(err)
+		}
 
-		return db
+		return applyFilters(db, filters)
 	}
 }
 
@@ -40,13 +123,53 @@ func Query(args *fasthttp.Args, allowedColumns map[string]bool) func(*gorm.DB) *
 // and checks the input columns against the allowedColumns list.
 // Returns a gorm query to be used in the function or an error.
 func Sort(args *fasthttp.Args, allowedColumns map[string]bool) func(*gorm.DB) *gorm.DB {
+	sort, err := ParseSort(args, allowedColumns)
+
 	return func(db *gorm.DB) *gorm.DB {
-		db = parseSortBy(args.Peek("sortBy"), db, allowedColumns)
+		if err != nil {
+			_ = db.AddError(err)
+		}
 
-		return db
+		return applySort(db, sort)
 	}
 }
 
+// ParseFilters parses the search params into filters
+// and checks the input columns against the allowedColumns list.
+// All invalid params are returned as joined *ParamError values.
+func ParseFilters(args *fasthttp.Args, allowedColumns map[string]bool) ([]Filter, error) {
+	var filters []Filter
+	var errs []error
+
+	parsers := []struct {
+		param string
+		parse func([]byte, map[string]bool) ([]Filter, []error)
+	}{
+		{param: "searchLike", parse: parseSearchLike},
+		{param: "searchEq", parse: parseSearchEq},
+		{param: "searchEqOr", parse: parseSearchEqOr},
+		{param: "searchLikeOr", parse: parseSearchLikeOr},
+		{param: "searchIn", parse: parseSearchIn},
+		{param: "searchBetween", parse: parseSearchBetween},
+	}
+	for _, parser := range parsers {
+		parsed, parseErrs := parser.parse(args.Peek(parser.param), allowedColumns)
+		filters = append(filters, parsed...)
+		errs = append(errs, withParam(parser.param, parseErrs)...)
+	}
+
+	return filters, errors.Join(errs...)
+}
+
+// ParseSort parses the sortBy param into sort fields
+// and checks the input columns against the allowedColumns list.
+// All invalid params are returned as joined *ParamError values.
+func ParseSort(args *fasthttp.Args, allowedColumns map[string]bool) ([]SortField, error) {
+	sort, errs := parseSortBy(args.Peek("sortBy"), allowedColumns)
+
+	return sort, errors.Join(withParam("sortBy", errs)...)
+}
+
 // Count calculates the page count with the given resultCount of a pagination query and a page limit.
 func Count(resultCount, limit int) int {
 	return int(math.Ceil(float64(resultCount) / float64(limit)))
@@ -68,115 +191,239 @@ func CreatePaginationModel(limit, page, pageCount, total int, result interface{}
 	}
 }
 
-// parseSearchLike Adds LIKE conditions to the GORM DB query
-// searchLike: for |where ... LIKE ... AND| query = searchLike=column:value,column:value =>
-// searchLike=firstname:john,lastname:doe
-func parseSearchLike(params []byte, db *gorm.DB, allowedColumns map[string]bool) *gorm.DB {
-	paramMap := parseSingleValueParams(db, string(params), allowedColumns)
-
-	for key, value := range paramMap {
-		db = db.Where(fmt.Sprintf("CAST(%s AS TEXT) ILIKE ?", parseColumn(key)), fmt.Sprintf("%%%s%%", value))
+// CreateCursorPaginationModel is a helper to be able to return a cursor pagination model in a single line
+func CreateCursorPaginationModel(limit int, nextCursor, prevCursor string, result interface{}) Model {
+	return Model{
+		Limit:      limit,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		Result:     result,
 	}
+}
 
-	return db
+// parseSearchLike parses LIKE conditions
+// searchLike: for |where ... LIKE ... AND| query = searchLike=column:value,column:value =>
+// searchLike=firstname:john,lastname:doe
+// searchLike=email:!@example.com => NOT ILIKE, searchLike=email:null => IS NULL
+func parseSearchLike(params []byte, allowedColumns map[string]bool) ([]Filter, []error) {
+	return parseConditionParams(Like, false, string(params), allowedColumns)
 }
 
-// parseSearchEq Adds equality conditions to the GORM DB query
+// parseSearchEq parses equality conditions
 // searchEq: for |where ... = ... AND| query = searchEq=column:value,column:value =>
 // searchEq=firstname:john,lastname:doe
-func parseSearchEq(params []byte, db *gorm.DB, allowedColumns map[string]bool) *gorm.DB {
-	paramMap := parseSingleValueParams(db, string(params), allowedColumns)
-
-	for key, value := range paramMap {
-		db = db.Where(fmt.Sprintf("CAST(%s AS TEXT) = ?", parseColumn(key)), value)
-	}
-
-	return db
+// searchEq=status:!archived => <>, searchEq=deletedAt:null => IS NULL, searchEq=deletedAt:!null => IS NOT NULL
+func parseSearchEq(params []byte, allowedColumns map[string]bool) ([]Filter, []error) {
+	return parseConditionParams(Equal, false, string(params), allowedColumns)
 }
 
-// parseOr merges searchEqOr and searchLikeOr into a single OR group that is AND-ed with other filters.
+// parseSearchEqOr parses equality conditions of the OR group
+// searchEqOr and searchLikeOr are merged into a single OR group that is AND-ed with other filters.
 // Example: searchEqOr=a:1,b:2 and searchLikeOr=c:x => WHERE (... AND (... OR ... OR ...))
-func parseOr(eqParams []byte, likeParams []byte, db *gorm.DB, allowedColumns map[string]bool) *gorm.DB {
-	var conditions []string
-	var values []interface{}
-
-	// Equal OR part
-	eqMap := parseSingleValueParams(db, string(eqParams), allowedColumns)
-	for key, value := range eqMap {
-		conditions = append(conditions, fmt.Sprintf("CAST(%s AS TEXT) = ?", parseColumn(key)))
-		values = append(values, value)
-	}
-
-	// LIKE OR part
-	likeMap := parseSingleValueParams(db, string(likeParams), allowedColumns)
-	for key, value := range likeMap {
-		conditions = append(conditions, fmt.Sprintf("CAST(%s AS TEXT) ILIKE ?", parseColumn(key)))
-		values = append(values, fmt.Sprintf("%%%s%%", value))
-	}
-
-	if len(conditions) > 0 {
-		group := "(" + strings.Join(conditions, " OR ") + ")"
-		db = db.Where(group, values...)
-	}
+func parseSearchEqOr(params []byte, allowedColumns map[string]bool) ([]Filter, []error) {
+	return parseConditionParams(Equal, true, string(params), allowedColumns)
+}
 
-	return db
+// parseSearchLikeOr parses LIKE conditions of the OR group, see parseSearchEqOr.
+func parseSearchLikeOr(params []byte, allowedColumns map[string]bool) ([]Filter, []error) {
+	return parseConditionParams(Like, true, string(params), allowedColumns)
 }
 
-// parseSearchIn Adds IN conditions to the GORM DB query
+// parseSearchIn parses IN conditions
 // searchIn: for |where IN| query = searchIn=column:value;value;value => searchIn=is_online:true;false
-func parseSearchIn(params []byte, db *gorm.DB, allowedColumns map[string]bool) *gorm.DB {
-	paramMap := parseMultiValueParams(db, string(params), allowedColumns)
+// searchIn=role:!admin;owner => NOT IN, searchIn=role:admin;null => IN ... OR IS NULL
+func parseSearchIn(params []byte, allowedColumns map[string]bool) ([]Filter, []error) {
+	paramList, errs := parseMultiValueParams(string(params), allowedColumns)
+
+	var filters []Filter
+	for _, p := range paramList {
+		cond, err := parseMultiCondition(p.values)
+		if err != nil {
+			errs = append(errs, &ParamError{Column: p.column, Err: err})
+			continue
+		}
 
-	for key, value := range paramMap {
-		db = db.Where(fmt.Sprintf("CAST(%s AS TEXT) IN (?)", parseColumn(key)), value)
+		filters = append(filters, Filter{
+			Column:   p.column,
+			Operator: In,
+			Values:   cond.values,
+			Null:     cond.null,
+			Negate:   cond.negate,
+		})
 	}
 
-	return db
+	return filters, errs
 }
 
-// parseSearchBetween Adds BETWEEN conditions to the GORM DB query
+// parseSearchBetween parses BETWEEN conditions
 // searchBetween: for |where ... between ... AND ...| query = searchBetween=column:value1;value2 =>
-// searchBetween=created_at:2020-08-03;2020-09-03
-func parseSearchBetween(params []byte, db *gorm.DB, allowedColumns map[string]bool) *gorm.DB {
-	paramMap := parseMultiValueParams(db, string(params), allowedColumns)
-
-	for key, value := range paramMap {
-		if len(value) != 2 {
-			_ = db.AddError(errors.New("not exactly two values for between query"))
+// searchBetween=created_at:2020-08-03T00:00:00Z;2020-09-03T00:00:00Z
+func parseSearchBetween(params []byte, allowedColumns map[string]bool) ([]Filter, []error) {
+	paramList, errs := parseMultiValueParams(string(params), allowedColumns)
+
+	var filters []Filter
+	for _, p := range paramList {
+		if len(p.values) != 2 {
+			errs = append(errs, &ParamError{Column: p.column, Err: errors.New("not exactly two values for between query")})
+			continue
 		}
 
 		// Parse the date-time strings
-		startTime, err1 := time.Parse(time.RFC3339, value[0])
-		endTime, err2 := time.Parse(time.RFC3339, value[1])
+		_, err1 := time.Parse(time.RFC3339, p.values[0])
+		_, err2 := time.Parse(time.RFC3339, p.values[1])
 		if err1 != nil || err2 != nil {
-			_ = db.AddError(errors.New("invalid date-time format"))
+			errs = append(errs, &ParamError{Column: p.column, Err: errors.New("invalid date-time format")})
+			continue
 		}
 
-		db = db.Where(fmt.Sprintf("%s BETWEEN ? AND ?", parseColumn(key)), startTime, endTime)
+		filters = append(filters, Filter{Column: p.column, Operator: Between, Values: p.values})
 	}
 
-	return db
+	return filters, errs
 }
 
-// parseSortBy Adds ORDER BY conditions to the GORM DB query
+// parseSortBy parses ORDER BY conditions
 // sortBy: for |ORDER BY| query = sortBy=column:value,column:value => sortBy=firstname:asc,lastname:desc
-func parseSortBy(params []byte, db *gorm.DB, allowedColumns map[string]bool) *gorm.DB {
-	paramMap := parseSingleValueParams(db, string(params), allowedColumns)
+func parseSortBy(params []byte, allowedColumns map[string]bool) ([]SortField, []error) {
+	paramList, errs := parseSingleValueParams(string(params), allowedColumns)
 
-	for key, value := range paramMap {
-		switch value {
+	var sort []SortField
+	for _, p := range paramList {
+		switch p.value {
 		case "desc":
-			db = db.Order(fmt.Sprintf("%s DESC", parseColumn(key)))
+			sort = append(sort, SortField{Column: p.column, Desc: true})
 		case "asc":
-			db = db.Order(fmt.Sprintf("%s ASC", parseColumn(key)))
+			sort = append(sort, SortField{Column: p.column})
 		default:
-			_ = db.AddError(errors.New("order not asc or desc"))
+			errs = append(errs, &ParamError{Column: p.column, Err: errors.New("order not asc or desc")})
+		}
+	}
+
+	return sort, errs
+}
+
+// applyFilters adds the filters to the GORM DB query.
+// Filters of the OR group are combined into a single OR clause that is AND-ed with the other filters.
+func applyFilters(db *gorm.DB, filters []Filter) *gorm.DB {
+	var orConditions []string
+	var orValues []interface{}
+
+	for _, filter := range filters {
+		query, values := filterClause(filter)
+		if filter.Or {
+			orConditions = append(orConditions, query)
+			orValues = append(orValues, values...)
+			continue
+		}
+
+		db = db.Where(query, values...)
+	}
+
+	if len(orConditions) > 0 {
+		group := "(" + strings.Join(orConditions, " OR ") + ")"
+		db = db.Where(group, orValues...)
+	}
+
+	return db
+}
+
+// applySort adds ORDER BY conditions to the GORM DB query.
+func applySort(db *gorm.DB, sort []SortField) *gorm.DB {
+	for _, field := range sort {
+		if field.Desc {
+			db = db.Order(fmt.Sprintf("%s DESC", parseColumn(field.Column)))
+		} else {
+			db = db.Order(fmt.Sprintf("%s ASC", parseColumn(field.Column)))
 		}
 	}
 
 	return db
 }
 
+// filterClause builds the SQL condition for a single filter.
+func filterClause(filter Filter) (string, []interface{}) {
+	switch filter.Operator {
+	case Like:
+		return likeClause(filter.Column, filter.condition())
+	case In:
+		return inClause(filter.Column, filter.multiCondition())
+	case Between:
+		startTime, _ := time.Parse(time.RFC3339, filter.Values[0])
+		endTime, _ := time.Parse(time.RFC3339, filter.Values[1])
+		return fmt.Sprintf("%s BETWEEN ? AND ?", parseColumn(filter.Column)), []interface{}{startTime, endTime}
+	default:
+		return eqClause(filter.Column, filter.condition())
+	}
+}
+
+// eqClause builds the equality condition for a single column.
+func eqClause(column string, cond condition) (string, []interface{}) {
+	if cond.null {
+		return nullClause(column, cond.negate), nil
+	}
+
+	operator := "="
+	if cond.negate {
+		operator = "<>"
+	}
+
+	return fmt.Sprintf("CAST(%s AS TEXT) %s ?", parseColumn(column), operator), []interface{}{cond.value}
+}
+
+// likeClause builds the ILIKE condition for a single column.
+func likeClause(column string, cond condition) (string, []interface{}) {
+	if cond.null {
+		return nullClause(column, cond.negate), nil
+	}
+
+	operator := "ILIKE"
+	if cond.negate {
+		operator = "NOT ILIKE"
+	}
+
+	return fmt.Sprintf("CAST(%s AS TEXT) %s ?", parseColumn(column), operator), []interface{}{fmt.Sprintf("%%%s%%", cond.value)}
+}
+
+// inClause builds the IN condition for a single column.
+// A null token in the list is OR-ed as IS NULL, or AND-ed as IS NOT NULL when negated.
+func inClause(column string, cond multiCondition) (string, []interface{}) {
+	var parts []string
+	var values []interface{}
+
+	if len(cond.values) > 0 {
+		operator := "IN"
+		if cond.negate {
+			operator = "NOT IN"
+		}
+		parts = append(parts, fmt.Sprintf("CAST(%s AS TEXT) %s (?)", parseColumn(column), operator))
+		values = append(values, cond.values)
+	}
+
+	if cond.null {
+		parts = append(parts, nullClause(column, cond.negate))
+	}
+
+	if len(parts) == 1 {
+		return parts[0], values
+	}
+
+	separator := " OR "
+	if cond.negate {
+		separator = " AND "
+	}
+
+	return "(" + strings.Join(parts, separator) + ")", values
+}
+
+// nullClause builds an IS NULL or IS NOT NULL condition for a single column.
+func nullClause(column string, negate bool) string {
+	if negate {
+		return fmt.Sprintf("%s IS NOT NULL", parseColumn(column))
+	}
+
+	return fmt.Sprintf("%s IS NULL", parseColumn(column))
+}
+
 // parseColumn quotes SQL identifiers correctly for GORM/SQL.
 // It splits the input on dots and wraps each non-empty part with double quotes,
 // so:
@@ -208,10 +455,112 @@ func parseColumn(column string) string {
 	return strings.Join(parts, ".")
 }
 
+// condition is a single filter value with its null and negation semantics.
+type condition struct {
+	value  string
+	null   bool
+	negate bool
+}
+
+// multiCondition is a list of filter values with their null and negation semantics.
+type multiCondition struct {
+	values []string
+	null   bool
+	negate bool
+}
+
+// parseConditionParams parses single value params into filters with the given operator.
+func parseConditionParams(operator Operator, or bool, params string, allowedColumns map[string]bool) ([]Filter, []error) {
+	paramList, errs := parseSingleValueParams(params, allowedColumns)
+
+	var filters []Filter
+	for _, p := range paramList {
+		cond, err := parseCondition(p.value)
+		if err != nil {
+			errs = append(errs, &ParamError{Column: p.column, Err: err})
+			continue
+		}
+
+		filter := Filter{Column: p.column, Operator: operator, Null: cond.null, Negate: cond.negate, Or: or}
+		if !cond.null {
+			filter.Values = []string{cond.value}
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, errs
+}
+
+// parseCondition parses a single filter value.
+//   - "null" matches NULL and "!null" matches NOT NULL
+//   - a leading "!" negates the value: "!archived"
+//   - a leading "\" escapes the tokens above: "\null" and "\!archived" are literal values
+func parseCondition(raw string) (condition, error) {
+	negate := strings.HasPrefix(raw, negationPrefix)
+	if negate {
+		raw = raw[len(negationPrefix):]
+	}
+
+	value, null := parseConditionValue(raw)
+	if value == "" && !null {
+		return condition{}, errors.New("cannot parse empty value")
+	}
+
+	return condition{value: value, null: null, negate: negate}, nil
+}
+
+// parseMultiCondition parses a list of filter values.
+// A leading "!" on the first value negates the whole list: "!admin;owner".
+// A "null" value in the list matches NULL next to the other values: "admin;null".
+func parseMultiCondition(raw []string) (multiCondition, error) {
+	cond := multiCondition{}
+
+	for i, value := range raw {
+		if i == 0 && strings.HasPrefix(value, negationPrefix) {
+			cond.negate = true
+			value = value[len(negationPrefix):]
+		}
+
+		value, null := parseConditionValue(value)
+		switch {
+		case null:
+			cond.null = true
+		case value == "":
+			return multiCondition{}, errors.New("cannot parse empty value in list")
+		default:
+			cond.values = append(cond.values, value)
+		}
+	}
+
+	return cond, nil
+}
+
+// parseConditionValue resolves the null token and strips the escape prefix from a filter value.
+func parseConditionValue(raw string) (string, bool) {
+	if raw == nullToken {
+		return "", true
+	}
+
+	return strings.TrimPrefix(raw, escapePrefix), false
+}
+
+// singleValueParam is a single column:value pair of a query param.
+type singleValueParam struct {
+	column string
+	value  string
+}
+
+// multiValueParam is a single column:value;value pair of a query param.
+type multiValueParam struct {
+	column string
+	values []string
+}
+
 // parseSingleValueParams parses the query string for single value params.
 // The query string should be in the format of key:value,key:value
-func parseSingleValueParams(db *gorm.DB, params string, allowedColumns map[string]bool) map[string]string {
-	paramMap := make(map[string]string)
+func parseSingleValueParams(params string, allowedColumns map[string]bool) ([]singleValueParam, []error) {
+	var paramList []singleValueParam
+	var errs []error
 
 	if params != "" {
 		paramSearchParts := strings.Split(params, ",")
@@ -220,7 +569,7 @@ func parseSingleValueParams(db *gorm.DB, params string, allowedColumns map[strin
 			// malformed when not exactly 2 parts or key empty
 			malformed := len(valuePairs) != 2 || valuePairs[0] == ""
 			if malformed {
-				_ = db.AddError(errors.New("cannot parse invalid format"))
+				errs = append(errs, &ParamError{Err: errors.New("cannot parse invalid format")})
 				continue
 			}
 
@@ -234,21 +583,22 @@ func parseSingleValueParams(db *gorm.DB, params string, allowedColumns map[strin
 
 			isAllowed := allowedColumns[key]
 			if !isAllowed {
-				_ = db.AddError(errors.New("column not allowed"))
+				errs = append(errs, &ParamError{Column: key, Err: errors.New("column not allowed")})
 				continue
 			}
 
-			paramMap[key] = val
+			paramList = append(paramList, singleValueParam{column: key, value: val})
 		}
 	}
 
-	return paramMap
+	return paramList, errs
 }
 
 // parseMultiValueParams parses the query string for multi value params.
-// The query string should be in the format of key:value.value.value,key:value.value.value
-func parseMultiValueParams(db *gorm.DB, params string, allowedColumns map[string]bool) map[string][]string {
-	paramMap := make(map[string][]string)
+// The query string should be in the format of key:value;value;value,key:value;value;value
+func parseMultiValueParams(params string, allowedColumns map[string]bool) ([]multiValueParam, []error) {
+	var paramList []multiValueParam
+	var errs []error
 
 	if params != "" {
 		paramSearchParts := strings.Split(params, ",")
@@ -257,7 +607,7 @@ func parseMultiValueParams(db *gorm.DB, params string, allowedColumns map[string
 			// malformed when not exactly 2 parts or key empty
 			malformed := len(valuePairs) != 2 || valuePairs[0] == ""
 			if malformed {
-				_ = db.AddError(errors.New("cannot parse invalid format"))
+				errs = append(errs, &ParamError{Err: errors.New("cannot parse invalid format")})
 				continue
 			}
 
@@ -271,13 +621,25 @@ func parseMultiValueParams(db *gorm.DB, params string, allowedColumns map[string
 
 			isAllowed := allowedColumns[key]
 			if !isAllowed {
-				_ = db.AddError(errors.New("column not allowed"))
+				errs = append(errs, &ParamError{Column: key, Err: errors.New("column not allowed")})
 				continue
 			}
 
-			paramMap[key] = strings.Split(val, ";")
+			paramList = append(paramList, multiValueParam{column: key, values: strings.Split(val, ";")})
+		}
+	}
+
+	return paramList, errs
+}
+
+// withParam sets the query param name on the given errors.
+func withParam(param string, errs []error) []error {
+	for _, err := range errs {
+		var paramErr *ParamError
+		if errors.As(err, &paramErr) {
+			paramErr.Param = param
 		}
 	}
 
-	return paramMap
+	return errs
 }
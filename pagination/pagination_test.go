@@ -1,6 +1,14 @@
 package pagination
 
-import "testing"
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
 
 func TestParseColumn(t *testing.T) {
 	cases := []struct {
@@ -26,3 +34,170 @@ func TestParseColumn(t *testing.T) {
 		}
 	}
 }
+
+func TestParseCondition(t *testing.T) {
+	cases := []struct {
+		in   string
+		out  condition
+		err  bool
+		desc string
+	}{
+		{in: "john", out: condition{value: "john"}, desc: "plain value"},
+		{in: "null", out: condition{null: true}, desc: "null token"},
+		{in: "!null", out: condition{null: true, negate: true}, desc: "negated null token"},
+		{in: "!archived", out: condition{value: "archived", negate: true}, desc: "negated value"},
+		{in: "\\null", out: condition{value: "null"}, desc: "escaped null token"},
+		{in: "\\!archived", out: condition{value: "!archived"}, desc: "escaped negation"},
+		{in: "!\\!archived", out: condition{value: "!archived", negate: true}, desc: "negated escaped negation"},
+		{in: "!", err: true, desc: "negation without value"},
+		{in: "\\", err: true, desc: "escape without value"},
+	}
+
+	for _, c := range cases {
+		got, err := parseCondition(c.in)
+		if c.err {
+			if err == nil {
+				t.Fatalf("%s: parseCondition(%q) expected error", c.desc, c.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: parseCondition(%q) unexpected error: %v", c.desc, c.in, err)
+		}
+		if got != c.out {
+			t.Fatalf("%s: parseCondition(%q) = %+v, want %+v", c.desc, c.in, got, c.out)
+		}
+	}
+}
+
+func TestEqClause(t *testing.T) {
+	cases := []struct {
+		in     string
+		query  string
+		values []interface{}
+		desc   string
+	}{
+		{in: "active", query: "CAST(\"status\" AS TEXT) = ?", values: []interface{}{"active"}, desc: "equal"},
+		{in: "!archived", query: "CAST(\"status\" AS TEXT) <> ?", values: []interface{}{"archived"}, desc: "not equal"},
+		{in: "null", query: "\"status\" IS NULL", desc: "is null"},
+		{in: "!null", query: "\"status\" IS NOT NULL", desc: "is not null"},
+		{in: "\\null", query: "CAST(\"status\" AS TEXT) = ?", values: []interface{}{"null"}, desc: "literal null"},
+	}
+
+	for _, c := range cases {
+		cond, err := parseCondition(c.in)
+		if err != nil {
+			t.Fatalf("%s: parseCondition(%q) unexpected error: %v", c.desc, c.in, err)
+		}
+		query, values := eqClause("status", cond)
+		if query != c.query || !reflect.DeepEqual(values, c.values) {
+			t.Fatalf("%s: eqClause(%q) = %q %v, want %q %v", c.desc, c.in, query, values, c.query, c.values)
+		}
+	}
+}
+
+func TestLikeClause(t *testing.T) {
+	cases := []struct {
+		in     string
+		query  string
+		values []interface{}
+		desc   string
+	}{
+		{in: "john", query: "CAST(\"name\" AS TEXT) ILIKE ?", values: []interface{}{"%john%"}, desc: "like"},
+		{in: "!john", query: "CAST(\"name\" AS TEXT) NOT ILIKE ?", values: []interface{}{"%john%"}, desc: "not like"},
+		{in: "null", query: "\"name\" IS NULL", desc: "is null"},
+		{in: "!null", query: "\"name\" IS NOT NULL", desc: "is not null"},
+	}
+
+	for _, c := range cases {
+		cond, err := parseCondition(c.in)
+		if err != nil {
+			t.Fatalf("%s: parseCondition(%q) unexpected error: %v", c.desc, c.in, err)
+		}
+		query, values := likeClause("name", cond)
+		if query != c.query || !reflect.DeepEqual(values, c.values) {
+			t.Fatalf("%s: likeClause(%q) = %q %v, want %q %v", c.desc, c.in, query, values, c.query, c.values)
+		}
+	}
+}
+
+func TestInClause(t *testing.T) {
+	cases := []struct {
+		in     []string
+		query  string
+		values []interface{}
+		err    bool
+		desc   string
+	}{
+		{in: []string{"admin", "owner"}, query: "CAST(\"role\" AS TEXT) IN (?)", values: []interface{}{[]string{"admin", "owner"}}, desc: "in"},
+		{in: []string{"!admin", "owner"}, query: "CAST(\"role\" AS TEXT) NOT IN (?)", values: []interface{}{[]string{"admin", "owner"}}, desc: "not in"},
+		{in: []string{"admin", "null"}, query: "(CAST(\"role\" AS TEXT) IN (?) OR \"role\" IS NULL)", values: []interface{}{[]string{"admin"}}, desc: "in or null"},
+		{in: []string{"!admin", "null"}, query: "(CAST(\"role\" AS TEXT) NOT IN (?) AND \"role\" IS NOT NULL)", values: []interface{}{[]string{"admin"}}, desc: "not in and not null"},
+		{in: []string{"null"}, query: "\"role\" IS NULL", desc: "only null"},
+		{in: []string{"!null"}, query: "\"role\" IS NOT NULL", desc: "only not null"},
+		{in: []string{"\\!admin", "\\null"}, query: "CAST(\"role\" AS TEXT) IN (?)", values: []interface{}{[]string{"!admin", "null"}}, desc: "escaped tokens"},
+		{in: []string{"admin", ""}, err: true, desc: "empty value in list"},
+		{in: []string{"!"}, err: true, desc: "negation without value"},
+	}
+
+	for _, c := range cases {
+		cond, err := parseMultiCondition(c.in)
+		if c.err {
+			if err == nil {
+				t.Fatalf("%s: parseMultiCondition(%q) expected error", c.desc, c.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: parseMultiCondition(%q) unexpected error: %v", c.desc, c.in, err)
+		}
+		query, values := inClause("role", cond)
+		if query != c.query || !reflect.DeepEqual(values, c.values) {
+			t.Fatalf("%s: inClause(%q) = %q %v, want %q %v", c.desc, c.in, query, values, c.query, c.values)
+		}
+	}
+}
+
+func TestQueryNullAndNegation(t *testing.T) {
+	db := newDryRunDB(t)
+
+	allowedColumns := map[string]bool{"status": true, "role": true, "deletedAt": true}
+	cases := []struct {
+		in   string
+		sql  string
+		desc string
+	}{
+		{in: "searchEq=deletedAt:null", sql: `WHERE "deletedAt" IS NULL`, desc: "eq null"},
+		{in: "searchEq=status:!archived", sql: `WHERE CAST("status" AS TEXT) <> 'archived'`, desc: "eq negated"},
+		{in: "searchLike=status:!arch", sql: `WHERE CAST("status" AS TEXT) NOT ILIKE '%arch%'`, desc: "like negated"},
+		{in: "searchIn=role:!admin;owner", sql: `WHERE CAST("role" AS TEXT) NOT IN ('admin','owner')`, desc: "in negated"},
+		{in: "searchEqOr=deletedAt:!null", sql: `WHERE ("deletedAt" IS NOT NULL)`, desc: "or not null"},
+	}
+
+	for _, c := range cases {
+		args := fasthttp.Args{}
+		args.Parse(c.in)
+
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Table("users").Scopes(Query(&args, allowedColumns)).Find(&[]map[string]interface{}{})
+		})
+		if !strings.HasSuffix(sql, c.sql) {
+			t.Fatalf("%s: Query(%q) = %q, want suffix %q", c.desc, c.in, sql, c.sql)
+		}
+	}
+}
+
+// newDryRunDB opens a postgres GORM DB that only builds SQL without connecting.
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	return db
+}
@@ -0,0 +1,389 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	errorsutil "github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/ArnoldPMolenaar/api-utils/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+// Define the default page limits.
+const (
+	defaultLimit    = 10
+	defaultMaxLimit = 100
+	// maxPage is the highest accepted page, so the offset of a page cannot overflow.
+	maxPage = 1_000_000
+)
+
+// DeletedVisibility controls whether soft-deleted rows are part of the result.
+type DeletedVisibility string
+
+// Define soft-delete visibilities as constants.
+const (
+	// ExcludeDeleted hides soft-deleted rows, the GORM default.
+	ExcludeDeleted DeletedVisibility = ""
+	// WithDeleted includes soft-deleted rows: withDeleted=true.
+	WithDeleted DeletedVisibility = "withDeleted"
+	// OnlyDeleted returns only soft-deleted rows: onlyDeleted=true.
+	OnlyDeleted DeletedVisibility = "onlyDeleted"
+)
+
+// ScopeFunc builds a GORM scope for the current request, e.g. to scope rows to the tenant of the user.
+type ScopeFunc func(c *fiber.Ctx) func(*gorm.DB) *gorm.DB
+
+// Config describes the pagination query params a list endpoint accepts.
+type Config struct {
+	// AllowedColumns are the columns that can be searched on.
+	AllowedColumns map[string]bool
+	// SortColumns are the columns that can be sorted on, defaults to AllowedColumns.
+	SortColumns map[string]bool
+	// DefaultSort is applied when the request has no sortBy param.
+	DefaultSort []SortField
+	// Fields are the columns that can be selected with the fields param.
+	Fields map[string]bool
+	// Includes are the relations that can be preloaded with the include param.
+	Includes map[string]bool
+	// DefaultLimit is applied when the request has no limit param, defaults to 10.
+	DefaultLimit int
+	// MaxLimit is the highest accepted limit, defaults to 100.
+	MaxLimit int
+	// Scopes are applied to every query before the filters of the request, e.g. tenant or owner scoping.
+	// They are always applied and cannot be overridden by the query params.
+	Scopes []ScopeFunc
+	// AllowDeleted reports whether the request may use the withDeleted and onlyDeleted params.
+	// The params are rejected when it is nil.
+	AllowDeleted func(c *fiber.Ctx) bool
+	// DeletedAtColumn is the soft-delete column used for onlyDeleted, defaults to deleted_at.
+	DeletedAtColumn string
+}
+
+// Request is a fully parsed pagination request.
+// In cursor mode Cursor is set and the page is ignored.
+type Request struct {
+	Page     int
+	Limit    int
+	Cursor   string
+	Filters  []Filter
+	Sort     []SortField
+	Fields   []string
+	Includes []string
+	Deleted  DeletedVisibility
+
+	deletedAtColumn string
+	scopes          []func(*gorm.DB) *gorm.DB
+}
+
+// Bind parses the pagination query params of the request with the given config
+// and resolves the mandatory scopes and soft-delete permission for the request.
+// When the params are invalid or not permitted, the standard error response is written
+// with an error per invalid param, and a nil Request
+// is returned together with the error of writing that response, so a handler can simply:
+//
+//	req, err := pagination.Bind(c, config)
+//	if req == nil {
+//		return err
+//	}
+func Bind(c *fiber.Ctx, config Config) (*Request, error) {
+	req, err := ParseRequest(c.Context().QueryArgs(), config)
+	if err != nil {
+		message := err.Error()
+		var paramErr *ParamError
+		if errors.As(err, &paramErr) {
+			message = paramErr.Error()
+		}
+
+		return nil, errorsutil.ResponseWithErrors(
+			c,
+			fiber.StatusBadRequest,
+			errorsutil.InvalidParam,
+			message,
+			FieldErrors(err),
+		)
+	}
+
+	if req.Deleted != ExcludeDeleted && !config.AllowDeleted(c) {
+		return nil, errorsutil.Response(
+			c,
+			fiber.StatusForbidden,
+			errorsutil.Forbidden,
+			fmt.Sprintf("%s is not permitted.", req.Deleted),
+		)
+	}
+
+	for _, scope := range config.Scopes {
+		req.scopes = append(req.scopes, scope(c))
+	}
+
+	return req, nil
+}
+
+// FieldErrors converts all *ParamError values in err's tree into field errors of an error response.
+func FieldErrors(err error) []errorsutil.FieldError {
+	var fieldErrors []errorsutil.FieldError
+
+	var paramErr *ParamError
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			fieldErrors = append(fieldErrors, FieldErrors(err)...)
+		}
+	default:
+		if errors.As(err, &paramErr) {
+			message := paramErr.Err.Error()
+			if paramErr.Column != "" {
+				message = fmt.Sprintf("%s: %s", paramErr.Column, message)
+			}
+
+			fieldErrors = append(fieldErrors, errorsutil.FieldError{
+				Param:   paramErr.Param,
+				Code:    errorsutil.InvalidParam,
+				Message: message,
+			})
+		}
+	}
+
+	return fieldErrors
+}
+
+// ParseRequest parses the pagination query params with the given config.
+// All invalid params are returned as joined *ParamError values.
+// The mandatory scopes and the AllowDeleted permission need the request and are only resolved by Bind.
+func ParseRequest(args *fasthttp.Args, config Config) (*Request, error) {
+	var errs []error
+	req := &Request{deletedAtColumn: config.DeletedAtColumn}
+	if req.deletedAtColumn == "" {
+		req.deletedAtColumn = "deleted_at"
+	}
+
+	page, err := parsePositiveInt(args.Peek("page"), 1, maxPage)
+	if err != nil {
+		errs = append(errs, &ParamError{Param: "page", Err: err})
+	}
+	req.Page = page
+
+	limit, err := parsePositiveInt(args.Peek("limit"), config.defaultLimit(), config.maxLimit())
+	if err != nil {
+		errs = append(errs, &ParamError{Param: "limit", Err: err})
+	}
+	req.Limit = limit
+	req.Cursor = string(args.Peek("cursor"))
+
+	req.Filters, err = ParseFilters(args, config.AllowedColumns)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	sortColumns := config.SortColumns
+	if sortColumns == nil {
+		sortColumns = config.AllowedColumns
+	}
+	req.Sort, err = ParseSort(args, sortColumns)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(req.Sort) == 0 {
+		req.Sort = config.DefaultSort
+	}
+
+	var listErrs []error
+	req.Fields, listErrs = parseListParam(args.Peek("fields"), config.Fields)
+	errs = append(errs, withParam("fields", listErrs)...)
+
+	req.Includes, listErrs = parseListParam(args.Peek("include"), config.Includes)
+	errs = append(errs, withParam("include", listErrs)...)
+
+	req.Deleted, err = parseDeletedVisibility(args, config.AllowDeleted != nil)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// Offset calculates the offset of the request.
+func (r *Request) Offset() int {
+	return Offset(r.Page, r.Limit)
+}
+
+// Model creates the pagination model of the request with the given total and result.
+func (r *Request) Model(total int, result interface{}) Model {
+	return CreatePaginationModel(r.Limit, r.Page, Count(total, r.Limit), total, result)
+}
+
+// CursorModel creates the cursor pagination model of the request with the given cursors and result.
+func (r *Request) CursorModel(nextCursor, prevCursor string, result interface{}) Model {
+	return CreateCursorPaginationModel(r.Limit, nextCursor, prevCursor, result)
+}
+
+// CountScopes returns the GORM scopes to count the total of the request:
+// mandatory scopes, soft-delete visibility and filters.
+func (r *Request) CountScopes() []func(*gorm.DB) *gorm.DB {
+	return []func(*gorm.DB) *gorm.DB{r.baseScope, r.filterScope}
+}
+
+// Scopes returns the GORM scopes to list a page of the request:
+// mandatory scopes, soft-delete visibility, filters, fields, includes, sort, offset and limit.
+// In cursor mode the offset is left out, the keyset condition of the cursor is up to the caller.
+func (r *Request) Scopes() []func(*gorm.DB) *gorm.DB {
+	return []func(*gorm.DB) *gorm.DB{r.baseScope, r.filterScope, r.selectScope, r.sortScope, r.pageScope}
+}
+
+// baseScope adds the mandatory scopes and the soft-delete visibility to the GORM DB query.
+// The filters of the request are AND-ed after it, so they can only narrow the mandatory scopes.
+func (r *Request) baseScope(db *gorm.DB) *gorm.DB {
+	for _, scope := range r.scopes {
+		db = scope(db)
+	}
+
+	switch r.Deleted {
+	case WithDeleted:
+		db = db.Unscoped()
+	case OnlyDeleted:
+		db = db.Unscoped().Where(nullClause(r.deletedAtColumn, true))
+	}
+
+	return db
+}
+
+// filterScope adds the filters to the GORM DB query.
+func (r *Request) filterScope(db *gorm.DB) *gorm.DB {
+	return applyFilters(db, r.Filters)
+}
+
+// selectScope adds the selected fields and preloaded includes to the GORM DB query.
+func (r *Request) selectScope(db *gorm.DB) *gorm.DB {
+	if len(r.Fields) > 0 {
+		fields := make([]string, len(r.Fields))
+		for i, field := range r.Fields {
+			fields[i] = parseColumn(field)
+		}
+		db = db.Select(fields)
+	}
+
+	for _, include := range r.Includes {
+		parts := strings.Split(include, ".")
+		for i, part := range parts {
+			parts[i] = utils.CamelcaseToPascalCase(part)
+		}
+		db = db.Preload(strings.Join(parts, "."))
+	}
+
+	return db
+}
+
+// sortScope adds the ORDER BY conditions to the GORM DB query.
+func (r *Request) sortScope(db *gorm.DB) *gorm.DB {
+	return applySort(db, r.Sort)
+}
+
+// pageScope adds the offset and limit to the GORM DB query.
+func (r *Request) pageScope(db *gorm.DB) *gorm.DB {
+	if r.Cursor != "" {
+		return db.Limit(r.Limit)
+	}
+
+	return db.Offset(r.Offset()).Limit(r.Limit)
+}
+
+// defaultLimit returns the configured default limit or the package default.
+func (c Config) defaultLimit() int {
+	if c.DefaultLimit > 0 {
+		return c.DefaultLimit
+	}
+
+	return defaultLimit
+}
+
+// maxLimit returns the configured max limit or the package default.
+func (c Config) maxLimit() int {
+	if c.MaxLimit > 0 {
+		return c.MaxLimit
+	}
+
+	return defaultMaxLimit
+}
+
+// parsePositiveInt parses a positive number param up to max or returns the fallback when it is empty.
+func parsePositiveInt(param []byte, fallback, max int) (int, error) {
+	if len(param) == 0 {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(string(param))
+	if err != nil || value < 1 {
+		return fallback, errors.New("must be a positive number")
+	}
+	if value > max {
+		return fallback, fmt.Errorf("must not be greater than %d", max)
+	}
+
+	return value, nil
+}
+
+// parseDeletedVisibility parses the withDeleted and onlyDeleted params.
+// withDeleted: withDeleted=true, onlyDeleted: onlyDeleted=true
+func parseDeletedVisibility(args *fasthttp.Args, allowed bool) (DeletedVisibility, error) {
+	var visibilities []DeletedVisibility
+	var errs []error
+
+	for _, visibility := range []DeletedVisibility{WithDeleted, OnlyDeleted} {
+		param := args.Peek(string(visibility))
+		if len(param) == 0 {
+			continue
+		}
+
+		enabled, err := strconv.ParseBool(string(param))
+		switch {
+		case err != nil:
+			errs = append(errs, &ParamError{Param: string(visibility), Err: errors.New("must be true or false")})
+		case enabled && !allowed:
+			errs = append(errs, &ParamError{Param: string(visibility), Err: errors.New("param not allowed")})
+		case enabled:
+			visibilities = append(visibilities, visibility)
+		}
+	}
+
+	if len(visibilities) > 1 {
+		errs = append(errs, &ParamError{Param: string(OnlyDeleted), Err: errors.New("cannot be combined with withDeleted")})
+	}
+	if err := errors.Join(errs...); err != nil {
+		return ExcludeDeleted, err
+	}
+	if len(visibilities) == 0 {
+		return ExcludeDeleted, nil
+	}
+
+	return visibilities[0], nil
+}
+
+// parseListParam parses a comma separated list param and checks the values against the allowed list.
+// The query string should be in the format of value,value => fields=id,name
+func parseListParam(param []byte, allowed map[string]bool) ([]string, []error) {
+	var values []string
+	var errs []error
+
+	if len(param) == 0 {
+		return values, errs
+	}
+
+	for _, value := range strings.Split(string(param), ",") {
+		if !allowed[value] {
+			errs = append(errs, &ParamError{Column: value, Err: errors.New("value not allowed")})
+			continue
+		}
+
+		values = append(values, value)
+	}
+
+	return values, errs
+}
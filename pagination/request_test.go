@@ -0,0 +1,234 @@
+package pagination
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+func TestParseRequest(t *testing.T) {
+	config := Config{
+		AllowedColumns: map[string]bool{"name": true, "role": true},
+		DefaultSort:    []SortField{{Column: "id"}},
+		Fields:         map[string]bool{"id": true, "name": true},
+		Includes:       map[string]bool{"roles": true},
+		MaxLimit:       50,
+	}
+
+	cases := []struct {
+		in   string
+		out  *Request
+		err  string
+		desc string
+	}{
+		{
+			in:   "",
+			out:  &Request{Page: 1, Limit: 10, Sort: []SortField{{Column: "id"}}, deletedAtColumn: "deleted_at"},
+			desc: "defaults",
+		},
+		{
+			in: "page=2&limit=20&searchEq=name:john&searchIn=role:!admin;null&sortBy=name:desc&fields=id,name&include=roles",
+			out: &Request{
+				Page:  2,
+				Limit: 20,
+				Filters: []Filter{
+					{Column: "name", Operator: Equal, Values: []string{"john"}},
+					{Column: "role", Operator: In, Values: []string{"admin"}, Null: true, Negate: true},
+				},
+				Sort:            []SortField{{Column: "name", Desc: true}},
+				Fields:          []string{"id", "name"},
+				Includes:        []string{"roles"},
+				deletedAtColumn: "deleted_at",
+			},
+			desc: "all params",
+		},
+		{in: "page=0", err: "page: must be a positive number", desc: "invalid page"},
+		{in: "page=9223372036854775807", err: "page: must not be greater than 1000000", desc: "page too high"},
+		{in: "limit=51", err: "limit: must not be greater than 50", desc: "limit too high"},
+		{in: "searchEq=email:john", err: "searchEq: email: column not allowed", desc: "column not allowed"},
+		{in: "searchEq=name", err: "searchEq: cannot parse invalid format", desc: "invalid format"},
+		{in: "sortBy=name:up", err: "sortBy: name: order not asc or desc", desc: "invalid order"},
+		{in: "fields=password", err: "fields: password: value not allowed", desc: "field not allowed"},
+		{in: "include=secrets", err: "include: secrets: value not allowed", desc: "include not allowed"},
+	}
+
+	for _, c := range cases {
+		args := fasthttp.Args{}
+		args.Parse(c.in)
+
+		got, err := ParseRequest(&args, config)
+		if c.err != "" {
+			var paramErr *ParamError
+			if !errors.As(err, &paramErr) || paramErr.Error() != c.err {
+				t.Fatalf("%s: ParseRequest(%q) error = %v, want %q", c.desc, c.in, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: ParseRequest(%q) unexpected error: %v", c.desc, c.in, err)
+		}
+		if !reflect.DeepEqual(got, c.out) {
+			t.Fatalf("%s: ParseRequest(%q) = %+v, want %+v", c.desc, c.in, got, c.out)
+		}
+	}
+}
+
+func TestRequestScopes(t *testing.T) {
+	db := newDryRunDB(t)
+	req := &Request{
+		Page:    3,
+		Limit:   10,
+		Filters: []Filter{{Column: "name", Operator: Like, Values: []string{"jo"}}},
+		Sort:    []SortField{{Column: "name", Desc: true}},
+		Fields:  []string{"id", "name"},
+	}
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Table("users").Scopes(req.Scopes()...).Find(&[]map[string]interface{}{})
+	})
+	want := `SELECT "id","name" FROM "users" WHERE CAST("name" AS TEXT) ILIKE '%jo%' ORDER BY "name" DESC LIMIT 10 OFFSET 20`
+	if sql != want {
+		t.Fatalf("Scopes() = %q, want %q", sql, want)
+	}
+
+	sql = db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var total int64
+		return tx.Table("users").Scopes(req.CountScopes()...).Count(&total)
+	})
+	if !strings.HasSuffix(sql, `WHERE CAST("name" AS TEXT) ILIKE '%jo%'`) || strings.Contains(sql, "LIMIT") {
+		t.Fatalf("CountScopes() = %q", sql)
+	}
+}
+
+type requestTestUser struct {
+	ID        uint
+	TenantID  uint
+	Name      string
+	DeletedAt gorm.DeletedAt
+}
+
+func TestBindScopesAndDeleted(t *testing.T) {
+	db := newDryRunDB(t)
+	config := Config{
+		AllowedColumns: map[string]bool{"name": true},
+		Scopes: []ScopeFunc{func(c *fiber.Ctx) func(*gorm.DB) *gorm.DB {
+			return func(db *gorm.DB) *gorm.DB {
+				return db.Where("tenant_id = ?", c.Locals("tenantID"))
+			}
+		}},
+		AllowDeleted: func(c *fiber.Ctx) bool {
+			return c.Get("x-role") == "admin"
+		},
+	}
+
+	cases := []struct {
+		target string
+		role   string
+		status int
+		sql    string
+		desc   string
+	}{
+		{
+			target: "/users?searchEqOr=name:john",
+			status: fiber.StatusOK,
+			sql:    `WHERE tenant_id = 7 AND (CAST("name" AS TEXT) = 'john') AND "request_test_users"."deleted_at" IS NULL`,
+			desc:   "mandatory scope and soft delete",
+		},
+		{
+			target: "/users?withDeleted=true",
+			role:   "admin",
+			status: fiber.StatusOK,
+			sql:    `WHERE tenant_id = 7 LIMIT 10`,
+			desc:   "with deleted",
+		},
+		{
+			target: "/users?onlyDeleted=true",
+			role:   "admin",
+			status: fiber.StatusOK,
+			sql:    `WHERE tenant_id = 7 AND "deleted_at" IS NOT NULL LIMIT 10`,
+			desc:   "only deleted",
+		},
+		{target: "/users?withDeleted=true", status: fiber.StatusForbidden, desc: "with deleted not permitted"},
+		{target: "/users?withDeleted=true&onlyDeleted=true", role: "admin", status: fiber.StatusBadRequest, desc: "combined"},
+	}
+
+	for _, c := range cases {
+		var sql string
+		app := fiber.New()
+		app.Get("/users", func(ctx *fiber.Ctx) error {
+			ctx.Locals("tenantID", 7)
+
+			req, err := Bind(ctx, config)
+			if req == nil {
+				return err
+			}
+
+			sql = db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Scopes(req.Scopes()...).Find(&[]requestTestUser{})
+			})
+
+			return ctx.SendStatus(fiber.StatusOK)
+		})
+
+		httpReq := httptest.NewRequest(fiber.MethodGet, c.target, nil)
+		httpReq.Header.Set("x-role", c.role)
+		resp, err := app.Test(httpReq)
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+		if resp.StatusCode != c.status {
+			t.Fatalf("%s: status = %d, want %d", c.desc, resp.StatusCode, c.status)
+		}
+		if !strings.Contains(sql, c.sql) {
+			t.Fatalf("%s: Scopes() = %q, want %q", c.desc, sql, c.sql)
+		}
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	config := Config{AllowedColumns: map[string]bool{"name": true}}
+
+	app := fiber.New()
+	app.Get("/users", func(ctx *fiber.Ctx) error {
+		req, err := Bind(ctx, config)
+		if req == nil {
+			return err
+		}
+
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users?page=0&searchEq=email:john", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+
+	var body struct {
+		Message string                   `json:"message"`
+		Errors  []map[string]interface{} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(body.Errors) != 2 {
+		t.Fatalf("errors = %v, want 2 errors", body.Errors)
+	}
+	for i, param := range []string{"page", "searchEq"} {
+		if body.Errors[i]["param"] != param || body.Errors[i]["code"] != "invalidParam" {
+			t.Fatalf("errors[%d] = %v, want param %s", i, body.Errors[i], param)
+		}
+	}
+	if !strings.HasPrefix(body.Message, "page") {
+		t.Fatalf("message = %q, want the first error", body.Message)
+	}
+}
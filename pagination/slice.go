@@ -0,0 +1,395 @@
+package pagination
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArnoldPMolenaar/api-utils/utils"
+)
+
+// Accessor returns the value of a column of an item and whether the column exists.
+// A nil value is treated as NULL.
+type Accessor[T any] func(item T, column string) (interface{}, bool)
+
+// Slice filters, sorts and paginates the items in memory with the same semantics as the SQL scopes.
+// Columns are resolved by reflection on struct fields by json tag, gorm column tag, field name
+// or camelCase field name, and on map keys. Nested fields are addressed with dots: address.city.
+// Fields and includes of the request are not applied.
+func Slice[T any](items []T, req *Request) (Model, error) {
+	return SliceFunc(items, req, ReflectAccessor[T]())
+}
+
+// SliceFunc filters, sorts and paginates the items in memory with the given accessor.
+// Values are compared as text like CAST(column AS TEXT), LIKE matches case-insensitive like ILIKE,
+// NULL never matches a comparison and NULLs sort last ascending and first descending.
+func SliceFunc[T any](items []T, req *Request, accessor Accessor[T]) (Model, error) {
+	if req.Cursor != "" {
+		return Model{}, errors.New("cursor pagination is not supported in memory")
+	}
+
+	var filtered []T
+	for _, item := range items {
+		ok, err := matchFilters(item, req.Filters, accessor)
+		if err != nil {
+			return Model{}, err
+		}
+		if ok {
+			filtered = append(filtered, item)
+		}
+	}
+
+	if err := sortSlice(filtered, req.Sort, accessor); err != nil {
+		return Model{}, err
+	}
+
+	total := len(filtered)
+	// Compute the offset only when it is within the items, so a huge page cannot overflow it.
+	start := total
+	if page := max(req.Page-1, 0); req.Limit > 0 && page <= total/req.Limit {
+		start = min(page*req.Limit, total)
+	}
+	end := start + min(max(req.Limit, 0), total-start)
+	result := make([]T, end-start)
+	copy(result, filtered[start:end])
+
+	return req.Model(total, result), nil
+}
+
+// ReflectAccessor returns an accessor that resolves columns by reflection, see Slice.
+func ReflectAccessor[T any]() Accessor[T] {
+	return func(item T, column string) (interface{}, bool) {
+		value := reflect.ValueOf(item)
+		for _, part := range strings.Split(column, ".") {
+			var ok bool
+			value, ok = lookupColumn(value, strings.TrimSpace(part))
+			if !ok {
+				return nil, false
+			}
+		}
+
+		if !value.IsValid() {
+			return nil, true
+		}
+
+		return value.Interface(), true
+	}
+}
+
+// lookupColumn resolves a single column name on a struct or map value.
+// An invalid value is returned for NULL when a pointer on the path is nil.
+func lookupColumn(value reflect.Value, column string) (reflect.Value, bool) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}, true
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Invalid:
+		return reflect.Value{}, true
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		field := value.MapIndex(reflect.ValueOf(column).Convert(value.Type().Key()))
+		return field, field.IsValid()
+	case reflect.Struct:
+		for _, field := range reflect.VisibleFields(value.Type()) {
+			if field.IsExported() && !field.Anonymous && matchesColumn(field, column) {
+				return value.FieldByIndex(field.Index), true
+			}
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// matchesColumn reports whether the struct field is addressed by the column name.
+func matchesColumn(field reflect.StructField, column string) bool {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" && name == column {
+		return true
+	}
+
+	for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(setting), "column:"); ok && name == column {
+			return true
+		}
+	}
+
+	return field.Name == column || utils.PascalCaseToCamelcase(field.Name) == column
+}
+
+// matchFilters reports whether the item matches all filters and at least one filter of the OR group.
+func matchFilters[T any](item T, filters []Filter, accessor Accessor[T]) (bool, error) {
+	hasOr, matchedOr := false, false
+
+	for _, filter := range filters {
+		value, ok := accessor(item, filter.Column)
+		if !ok {
+			return false, fmt.Errorf("column %q not found", filter.Column)
+		}
+
+		matched, err := matchFilter(value, filter)
+		if err != nil {
+			return false, err
+		}
+
+		if filter.Or {
+			hasOr = true
+			matchedOr = matchedOr || matched
+		} else if !matched {
+			return false, nil
+		}
+	}
+
+	return !hasOr || matchedOr, nil
+}
+
+// matchFilter reports whether the value matches a single filter, see filterClause for the SQL equivalent.
+func matchFilter(value interface{}, filter Filter) (bool, error) {
+	value, err := normalizeValue(value)
+	if err != nil {
+		return false, err
+	}
+	isNull := value == nil
+
+	switch filter.Operator {
+	case Between:
+		if isNull {
+			return false, nil
+		}
+		startTime, _ := time.Parse(time.RFC3339, filter.Values[0])
+		endTime, _ := time.Parse(time.RFC3339, filter.Values[1])
+		t, ok := value.(time.Time)
+		if !ok {
+			if t, err = time.Parse(time.RFC3339, textValue(value)); err != nil {
+				return false, fmt.Errorf("column %q is not a date-time", filter.Column)
+			}
+		}
+		return !t.Before(startTime) && !t.After(endTime), nil
+	case In:
+		// IN ... OR IS NULL, or NOT IN ... AND IS NOT NULL when negated.
+		if isNull {
+			return filter.Null && !filter.Negate, nil
+		}
+		text := textValue(value)
+		found := false
+		for _, v := range filter.Values {
+			found = found || v == text
+		}
+		if filter.Negate {
+			return !found, nil
+		}
+		return found, nil
+	}
+
+	if filter.Null {
+		return isNull != filter.Negate, nil
+	}
+	if isNull {
+		return false, nil
+	}
+
+	var matched bool
+	if filter.Operator == Like {
+		matched = matchILike(textValue(value), fmt.Sprintf("%%%s%%", filter.Values[0]))
+	} else {
+		matched = textValue(value) == filter.Values[0]
+	}
+
+	return matched != filter.Negate, nil
+}
+
+// sortSlice sorts the items stable by the sort fields.
+func sortSlice[T any](items []T, sortFields []SortField, accessor Accessor[T]) error {
+	var err error
+
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, field := range sortFields {
+			a, okA := accessor(items[i], field.Column)
+			b, okB := accessor(items[j], field.Column)
+			if !okA || !okB {
+				err = fmt.Errorf("column %q not found", field.Column)
+				return false
+			}
+
+			a, errA := normalizeValue(a)
+			b, errB := normalizeValue(b)
+			if err = errors.Join(errA, errB); err != nil {
+				return false
+			}
+
+			cmp := compareValues(a, b)
+			if field.Desc {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+
+		return false
+	})
+
+	return err
+}
+
+// normalizeValue dereferences pointers and driver values to a plain value, nil for NULL.
+func normalizeValue(value interface{}) (interface{}, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil, nil
+		}
+
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	return rv.Interface(), nil
+}
+
+// textValue formats a normalized value like Postgres does on CAST(value AS TEXT).
+func textValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		text := v.Format("2006-01-02 15:04:05.999999-07:00")
+		return strings.TrimSuffix(text, ":00")
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareValues compares two normalized values by their type, NULL is greater than any value.
+func compareValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Compare(tb)
+		}
+	}
+	if ba, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			switch {
+			case ba == bb:
+				return 0
+			case bb:
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+	if fa, ok := numberValue(a); ok {
+		if fb, ok := numberValue(b); ok {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(textValue(a), textValue(b))
+}
+
+// numberValue converts a numeric value to a float64.
+func numberValue(value interface{}) (float64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// matchILike matches the text case-insensitive against a LIKE pattern,
+// where % matches any sequence, _ matches a single character and \ escapes the next character.
+func matchILike(text, pattern string) bool {
+	t := []rune(strings.ToLower(text))
+	p := []rune(strings.ToLower(pattern))
+
+	// Match greedily and on a mismatch backtrack only to the last %, which is linear in the text per pattern segment.
+	ti, pi := 0, 0
+	star, starTi := -1, 0
+	for ti < len(t) {
+		if pi < len(p) && p[pi] == '%' {
+			star, starTi = pi, ti
+			pi++
+			continue
+		}
+
+		if pi < len(p) {
+			literal, width := p[pi], 1
+			if literal == '\\' && pi+1 < len(p) {
+				literal, width = p[pi+1], 2
+			}
+			if literal == '_' && width == 1 || literal == t[ti] {
+				ti++
+				pi += width
+				continue
+			}
+		}
+
+		if star < 0 {
+			return false
+		}
+		starTi++
+		ti, pi = starTi, star+1
+	}
+
+	for pi < len(p) && p[pi] == '%' {
+		pi++
+	}
+
+	return pi == len(p)
+}
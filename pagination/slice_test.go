@@ -0,0 +1,131 @@
+package pagination
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+type sliceTestAddress struct {
+	City string `json:"city"`
+}
+
+type sliceTestUser struct {
+	ID        uint              `json:"id"`
+	Name      string            `json:"name"`
+	Role      *string           `json:"role"`
+	Active    bool              `gorm:"column:is_active"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Address   *sliceTestAddress `json:"address"`
+}
+
+func TestSlice(t *testing.T) {
+	admin, owner := "admin", "owner"
+	users := []sliceTestUser{
+		{ID: 1, Name: "John", Role: &admin, Active: true, CreatedAt: time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC), Address: &sliceTestAddress{City: "Amsterdam"}},
+		{ID: 2, Name: "Jane", Role: &owner, CreatedAt: time.Date(2020, 8, 15, 0, 0, 0, 0, time.UTC), Address: &sliceTestAddress{City: "Berlin"}},
+		{ID: 3, Name: "Joe_", CreatedAt: time.Date(2020, 9, 15, 0, 0, 0, 0, time.UTC)},
+		{ID: 4, Name: "Anna", Role: &admin, Active: true, CreatedAt: time.Date(2020, 8, 20, 0, 0, 0, 0, time.UTC), Address: &sliceTestAddress{City: "Amsterdam"}},
+	}
+	config := Config{AllowedColumns: map[string]bool{
+		"id": true, "name": true, "role": true, "is_active": true, "createdAt": true, "address.city": true,
+	}}
+
+	cases := []struct {
+		in    string
+		ids   []uint
+		total int
+		desc  string
+	}{
+		{in: "", ids: []uint{1, 2, 3, 4}, total: 4, desc: "no filters"},
+		{in: "searchEq=role:admin", ids: []uint{1, 4}, total: 2, desc: "equal"},
+		{in: "searchEq=role:!admin", ids: []uint{2}, total: 1, desc: "not equal excludes null"},
+		{in: "searchEq=role:null", ids: []uint{3}, total: 1, desc: "is null"},
+		{in: "searchEq=address.city:!null", ids: []uint{1, 2, 4}, total: 3, desc: "nested is not null"},
+		{in: "searchEq=is_active:true", ids: []uint{1, 4}, total: 2, desc: "bool as text"},
+		{in: "searchLike=name:JO", ids: []uint{1, 3}, total: 2, desc: "like case-insensitive"},
+		{in: "searchLike=name:n_a", ids: []uint{4}, total: 1, desc: "like underscore wildcard"},
+		{in: "searchLike=name:!j", ids: []uint{4}, total: 1, desc: "not like"},
+		{in: "searchIn=role:owner;null", ids: []uint{2, 3}, total: 2, desc: "in or null"},
+		{in: "searchIn=role:!owner;null", ids: []uint{1, 4}, total: 2, desc: "not in and not null"},
+		{in: "searchEqOr=name:Jane,id:4&searchEq=is_active:true", ids: []uint{4}, total: 1, desc: "or group"},
+		{in: "searchBetween=createdAt:2020-08-10T00:00:00Z;2020-08-31T00:00:00Z", ids: []uint{2, 4}, total: 2, desc: "between"},
+		{in: "sortBy=role:asc,name:desc", ids: []uint{1, 4, 2, 3}, total: 4, desc: "sort nulls last"},
+		{in: "sortBy=role:desc,id:asc", ids: []uint{3, 2, 1, 4}, total: 4, desc: "sort desc nulls first"},
+		{in: "sortBy=createdAt:desc&limit=2&page=2", ids: []uint{2, 1}, total: 4, desc: "paginate"},
+		{in: "limit=3&page=3", ids: []uint{}, total: 4, desc: "page out of range"},
+	}
+
+	for _, c := range cases {
+		args := fasthttp.Args{}
+		args.Parse(c.in)
+
+		req, err := ParseRequest(&args, config)
+		if err != nil {
+			t.Fatalf("%s: ParseRequest(%q) unexpected error: %v", c.desc, c.in, err)
+		}
+
+		model, err := Slice(users, req)
+		if err != nil {
+			t.Fatalf("%s: Slice(%q) unexpected error: %v", c.desc, c.in, err)
+		}
+
+		ids := []uint{}
+		for _, user := range model.Result.([]sliceTestUser) {
+			ids = append(ids, user.ID)
+		}
+		if !reflect.DeepEqual(ids, c.ids) || model.Total != c.total {
+			t.Fatalf("%s: Slice(%q) = %v (total %d), want %v (total %d)", c.desc, c.in, ids, model.Total, c.ids, c.total)
+		}
+	}
+}
+
+func TestSliceUnknownColumn(t *testing.T) {
+	req := &Request{Page: 1, Limit: 10, Filters: []Filter{{Column: "missing", Operator: Equal, Values: []string{"x"}}}}
+
+	if _, err := Slice([]sliceTestUser{{ID: 1}}, req); err == nil {
+		t.Fatalf("Slice() expected error for unknown column")
+	}
+}
+
+func TestSliceOffsetOverflow(t *testing.T) {
+	req := &Request{Page: math.MaxInt, Limit: 10}
+
+	model, err := Slice([]sliceTestUser{{ID: 1}}, req)
+	if err != nil {
+		t.Fatalf("Slice() unexpected error: %v", err)
+	}
+	if result := model.Result.([]sliceTestUser); len(result) != 0 {
+		t.Fatalf("Slice() = %v, want no result", result)
+	}
+}
+
+func TestMatchILike(t *testing.T) {
+	cases := []struct {
+		text    string
+		pattern string
+		want    bool
+		desc    string
+	}{
+		{text: "John", pattern: "jo%", want: true, desc: "prefix"},
+		{text: "John", pattern: "%HN", want: true, desc: "suffix case-insensitive"},
+		{text: "John", pattern: "j_hn", want: true, desc: "underscore"},
+		{text: "John", pattern: "j_n", want: false, desc: "underscore is one character"},
+		{text: "Joe_", pattern: `%e\_`, want: true, desc: "escaped underscore"},
+		{text: "Joey", pattern: `%e\_`, want: false, desc: "escaped underscore is literal"},
+		{text: "abcabd", pattern: "%ab%d", want: true, desc: "backtrack to last percent"},
+		{text: "", pattern: "%%", want: true, desc: "empty text"},
+		{text: "a", pattern: "", want: false, desc: "empty pattern"},
+		{text: strings.Repeat("a", 60), pattern: "%a%a%a%a%a%a%a%a%a%a%a%b", want: false, desc: "pathological pattern"},
+	}
+
+	for _, c := range cases {
+		if got := matchILike(c.text, c.pattern); got != c.want {
+			t.Fatalf("%s: matchILike(%q, %q) = %t, want %t", c.desc, c.text, c.pattern, got, c.want)
+		}
+	}
+}
@@ -2,12 +2,33 @@ package utils
 
 import (
 	"errors"
+	"log/slog"
+	"runtime/debug"
+
 	errorsutil "github.com/ArnoldPMolenaar/api-utils/errors"
 	"github.com/gofiber/fiber/v2"
 )
 
 // ErrorHandler is a custom error handler for Fiber.
+// GORM and Postgres errors and bare error codes are translated into API errors, see errors.Translate.
+// Internal errors are logged with their stack trace and request ID.
+// In production, see IsDevelopment, their message is replaced by a generic message
+// unless the error is marked safe to expose with errors.Safe.
+// Panics are recovered by the middleware.Recover middleware.
 func ErrorHandler(c *fiber.Ctx, err error) error {
+	// Translate database errors into API errors.
+	err = errorsutil.Translate(err)
+
+	// Check if it's an API error that carries its own response.
+	var apiErr *errorsutil.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Status >= fiber.StatusInternalServerError {
+			logError(c, err)
+		}
+
+		return errorsutil.ErrorResponse(c, apiErr)
+	}
+
 	// Default to 500 Internal Server Error.
 	code := fiber.StatusInternalServerError
 	var message string
@@ -18,8 +39,13 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 		code = e.Code
 		message = e.Message
 	} else {
-		// If it's a panic error, include the panic message.
-		message = err.Error()
+		logError(c, err)
+
+		// Only expose the message of internal errors in development or when marked safe.
+		message = errorsutil.InternalErrorMessage
+		if IsDevelopment() || errorsutil.IsSafe(err) {
+			message = err.Error()
+		}
 	}
 
 	// Return the error response as JSON.
@@ -30,3 +56,21 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 		message,
 	)
 }
+
+// logError logs the error with its stack trace with the request-scoped logger, see Logger,
+// which includes the request ID that is returned to the client.
+// The stack trace where the error was created is used when recorded, otherwise the current stack trace.
+func logError(c *fiber.Ctx, err error) {
+	stack := errorsutil.Stack(err)
+	if stack == "" {
+		stack = string(debug.Stack())
+	}
+
+	Logger(c).Error(
+		"request failed",
+		slog.String("method", c.Method()),
+		slog.String("path", c.Path()),
+		slog.String("error", err.Error()),
+		slog.String("stack", stack),
+	)
+}
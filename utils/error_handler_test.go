@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	errorsutil "github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestErrorHandler(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	cases := []struct {
+		stage   string
+		err     error
+		status  int
+		code    string
+		message string
+		desc    string
+	}{
+		{
+			stage:   "prod",
+			err:     errors.New("pq: relation \"users\" does not exist"),
+			status:  fiber.StatusInternalServerError,
+			code:    string(errorsutil.InternalServerError),
+			message: errorsutil.InternalErrorMessage,
+			desc:    "internal error hidden in production",
+		},
+		{
+			stage:   "dev",
+			err:     errors.New("pq: relation \"users\" does not exist"),
+			status:  fiber.StatusInternalServerError,
+			code:    string(errorsutil.InternalServerError),
+			message: "pq: relation \"users\" does not exist",
+			desc:    "internal error shown in development",
+		},
+		{
+			stage:   "prod",
+			err:     errorsutil.Safe(errors.New("upstream is unavailable")),
+			status:  fiber.StatusInternalServerError,
+			code:    string(errorsutil.InternalServerError),
+			message: "upstream is unavailable",
+			desc:    "safe error shown in production",
+		},
+		{
+			stage:   "prod",
+			err:     errorsutil.Wrap(errors.New("no rows"), fiber.StatusNotFound, errorsutil.NotFound, "User not found."),
+			status:  fiber.StatusNotFound,
+			code:    string(errorsutil.NotFound),
+			message: "User not found.",
+			desc:    "api error",
+		},
+		{
+			stage:   "prod",
+			err:     errorsutil.NotFound,
+			status:  fiber.StatusNotFound,
+			code:    string(errorsutil.NotFound),
+			message: "Resource not found.",
+			desc:    "bare code",
+		},
+		{
+			stage:   "prod",
+			err:     fiber.ErrBadRequest,
+			status:  fiber.StatusBadRequest,
+			code:    string(errorsutil.InternalServerError),
+			message: "Bad Request",
+			desc:    "fiber error",
+		},
+	}
+
+	for _, c := range cases {
+		t.Setenv("STAGE_STATUS", c.stage)
+
+		app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		app.Get("/", func(ctx *fiber.Ctx) error {
+			return c.err
+		})
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", c.desc, err)
+		}
+
+		var body struct {
+			Code      string `json:"code"`
+			Message   string `json:"message"`
+			RequestID string `json:"requestId"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: decode body: %v", c.desc, err)
+		}
+		if resp.StatusCode != c.status || body.Code != c.code || body.Message != c.message {
+			t.Fatalf("%s: response = %d %+v, want %d %s %q", c.desc, resp.StatusCode, body, c.status, c.code, c.message)
+		}
+		if c.status == fiber.StatusInternalServerError && body.RequestID == "" {
+			t.Fatalf("%s: response has no request ID", c.desc)
+		}
+	}
+}
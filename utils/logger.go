@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+
+	errorsutil "github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// loggerLocalsKey is the key of the request-scoped logger in c.Locals.
+const loggerLocalsKey = "logger"
+
+// loggerContextKey is the key of the request-scoped logger in a context.
+type loggerContextKey struct{}
+
+// SetLogger stores the request-scoped logger on the request and in its user context,
+// so it is also available to code that only receives the context, see LoggerFromContext.
+func SetLogger(c *fiber.Ctx, logger *slog.Logger) {
+	c.Locals(loggerLocalsKey, logger)
+	c.SetUserContext(context.WithValue(c.UserContext(), loggerContextKey{}, logger))
+}
+
+// Logger returns the request-scoped logger of the request, see middleware.RequestLogger.
+// Without it, the default logger with the request ID is returned.
+func Logger(c *fiber.Ctx) *slog.Logger {
+	if logger, ok := c.Locals(loggerLocalsKey).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default().With(slog.String("requestId", errorsutil.RequestID(c)))
+}
+
+// LoggerFromContext returns the request-scoped logger of the context, the default logger when not set.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
@@ -0,0 +1,9 @@
+package utils
+
+import "os"
+
+// IsDevelopment reports whether STAGE_STATUS=dev is configured in the .env file.
+// Any other stage is treated as production.
+func IsDevelopment() bool {
+	return os.Getenv("STAGE_STATUS") == "dev"
+}
@@ -2,7 +2,7 @@ package utils
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 )
@@ -22,7 +22,7 @@ func StartServerWithGracefulShutdown(a *fiber.App) {
 		// Received an interrupt signal, shutdown.
 		if err := a.Shutdown(); err != nil {
 			// Error from closing listeners, or context timeout:
-			log.Printf("Oops... Server is not shutting down! Reason: %v", err)
+			slog.Error("Oops... Server is not shutting down!", slog.String("error", err.Error()))
 		}
 
 		close(idleConnectionClosed)
@@ -33,7 +33,7 @@ func StartServerWithGracefulShutdown(a *fiber.App) {
 
 	// Run server.
 	if err := a.Listen(fiberConnURL); err != nil {
-		log.Printf("Oops... Server is not running! Reason: %v", err)
+		slog.Error("Oops... Server is not running!", slog.String("error", err.Error()))
 	}
 
 	<-idleConnectionClosed
@@ -46,6 +46,6 @@ func StartServer(a *fiber.App) {
 
 	// Run server.
 	if err := a.Listen(fiberConnectionURL); err != nil {
-		log.Printf("Oops... Server is not running! Reason: %v", err)
+		slog.Error("Oops... Server is not running!", slog.String("error", err.Error()))
 	}
 }
@@ -1,11 +1,17 @@
 package utils
 
 import (
+	"strings"
+
+	errorsutil "github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/ArnoldPMolenaar/api-utils/i18n"
 	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 // NewValidator func for create a new validator for model fields.
+// The validation messages of the i18n bundles are registered, see LocalizedValidatorErrors.
 //
 //	validate := utils.NewValidator()
 //	if err := validate.Struct(object); err != nil { }
@@ -21,6 +27,9 @@ func NewValidator() *validator.Validate {
 		return false
 	})
 
+	// Register the translated validation messages.
+	_ = i18n.RegisterValidatorTranslations(validate)
+
 	return validate
 }
 
@@ -36,3 +45,62 @@ func ValidatorErrors(err error) map[string]string {
 
 	return fields
 }
+
+// LocalizedValidatorErrors func for show translated validation errors for each invalid fields.
+// The messages are translated to the Accept-Language of the request.
+// The validator must be created with NewValidator.
+func LocalizedValidatorErrors(c *fiber.Ctx, err error) map[string]string {
+	// Define fields map.
+	fields := map[string]string{}
+
+	// Make translated error message for each invalid field.
+	trans := i18n.ValidatorTranslator(c)
+	for _, err := range err.(validator.ValidationErrors) {
+		fields[err.Field()] = err.Translate(trans)
+	}
+
+	return fields
+}
+
+// ValidatorFieldErrors func for show translated validation errors as field errors of an error response.
+// The pointer of each field error is built from the camelCase names of the struct fields,
+// so User.Items[0].Name becomes /items/0/name.
+//
+//	return errors.New(fiber.StatusBadRequest, errors.Validator, "Request body is invalid.").
+//		WithErrors(utils.ValidatorFieldErrors(c, err)...)
+func ValidatorFieldErrors(c *fiber.Ctx, err error) []errorsutil.FieldError {
+	// Define field errors slice.
+	var fieldErrors []errorsutil.FieldError
+
+	// Make translated field error for each invalid field.
+	trans := i18n.ValidatorTranslator(c)
+	for _, err := range err.(validator.ValidationErrors) {
+		fieldErrors = append(fieldErrors, errorsutil.FieldError{
+			Pointer: namespacePointer(err.Namespace()),
+			Code:    errorsutil.Validator,
+			Message: err.Translate(trans),
+		})
+	}
+
+	return fieldErrors
+}
+
+// namespacePointer converts a validator namespace into a JSON pointer without the struct name.
+func namespacePointer(namespace string) string {
+	var segments []interface{}
+
+	parts := strings.Split(namespace, ".")
+	for _, part := range parts[1:] {
+		// Split indexed fields like Items[0] into the field and its index.
+		name, rest, _ := strings.Cut(part, "[")
+		segments = append(segments, PascalCaseToCamelcase(name))
+		for rest != "" {
+			var index string
+			index, rest, _ = strings.Cut(rest, "]")
+			segments = append(segments, index)
+			rest = strings.TrimPrefix(rest, "[")
+		}
+	}
+
+	return errorsutil.Pointer(segments...)
+}
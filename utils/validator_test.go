@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	errorsutil "github.com/ArnoldPMolenaar/api-utils/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+func TestValidatorFieldErrors(t *testing.T) {
+	type item struct {
+		Name string `validate:"required"`
+	}
+	type order struct {
+		CustomerID string `validate:"required"`
+		Items      []item `validate:"dive"`
+	}
+
+	app := fiber.New()
+	ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(ctx)
+
+	err := NewValidator().Struct(order{Items: []item{{Name: "a"}, {}}})
+	if err == nil {
+		t.Fatalf("Struct() = nil, want validation errors")
+	}
+
+	want := []errorsutil.FieldError{
+		{Pointer: "/customerID", Code: errorsutil.Validator, Message: "CustomerID is a required field"},
+		{Pointer: "/items/1/name", Code: errorsutil.Validator, Message: "Name is a required field"},
+	}
+	if got := ValidatorFieldErrors(ctx, err); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ValidatorFieldErrors() = %+v, want %+v", got, want)
+	}
+}